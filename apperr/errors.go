@@ -0,0 +1,95 @@
+package apperr
+
+import "errors"
+
+// Code — код ошибки API, возвращается клиенту в поле error.code
+type Code string
+
+const (
+	CodeBadRequest         Code = "BAD_REQUEST"
+	CodeNotFound           Code = "NOT_FOUND"
+	CodeTeamExists         Code = "TEAM_EXISTS"
+	CodePRExists           Code = "PR_EXISTS"
+	CodePRMerged           Code = "PR_MERGED"
+	CodeNotAssigned        Code = "NOT_ASSIGNED"
+	CodeNoCandidate        Code = "NO_CANDIDATE"
+	CodeInvalidTransition  Code = "INVALID_TRANSITION"
+	CodeMergeBlocked       Code = "MERGE_BLOCKED"
+	CodeNotEnoughReviewers Code = "NOT_ENOUGH_REVIEWERS"
+	CodeUserInTeam         Code = "USER_IN_TEAM"
+	CodeIdentityTaken      Code = "IDENTITY_TAKEN"
+	CodeUnauthorized       Code = "UNAUTHORIZED"
+	CodeForbidden          Code = "FORBIDDEN"
+	CodeInternal           Code = "INTERNAL_ERROR"
+)
+
+// Error — ошибка предметной области с кодом API.
+// Details добавляются в тело ответа рядом с code и message.
+type Error struct {
+	Code    Code
+	Message string
+	Details map[string]interface{}
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is сравнивает ошибки по коду и сообщению; ошибка без сообщения
+// совпадает с любой ошибкой того же кода
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok {
+		return false
+	}
+	return t.Code == e.Code && (t.Message == "" || t.Message == e.Message)
+}
+
+// WithDetails возвращает копию ошибки с дополнительным полем ответа
+func (e *Error) WithDetails(key string, value interface{}) *Error {
+	details := make(map[string]interface{}, len(e.Details)+1)
+	for k, v := range e.Details {
+		details[k] = v
+	}
+	details[key] = value
+
+	return &Error{Code: e.Code, Message: e.Message, Details: details}
+}
+
+// From возвращает ошибку предметной области из цепочки err
+func From(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
+
+// Ошибки, общие для сервиса и репозитория
+var (
+	ErrNotFound           = New(CodeNotFound, "")
+	ErrTeamNotFound       = New(CodeNotFound, "team not found")
+	ErrUserNotFound       = New(CodeNotFound, "user not found")
+	ErrPRNotFound         = New(CodeNotFound, "PR not found")
+	ErrAbsenceNotFound    = New(CodeNotFound, "absence not found")
+	ErrWebhookNotFound    = New(CodeNotFound, "webhook subscription not found")
+	ErrDeadLetterNotFound = New(CodeNotFound, "dead letter not found")
+	ErrIdentityNotFound   = New(CodeNotFound, "identity not found")
+	ErrAuthorNotFound     = New(CodeNotFound, "author/team not found")
+	ErrInvalidBody        = New(CodeBadRequest, "invalid request body")
+	ErrTeamExists         = New(CodeTeamExists, "team_name already exists")
+	ErrPRExists           = New(CodePRExists, "PR id already exists")
+	ErrIdentityTaken      = New(CodeIdentityTaken, "identity is attached to another user")
+	ErrPRMerged           = New(CodePRMerged, "cannot reassign on merged PR")
+	ErrNotAssigned        = New(CodeNotAssigned, "reviewer is not assigned to this PR")
+	ErrNoCandidate        = New(CodeNoCandidate, "no active replacement candidate in team")
+	ErrInvalidTransition  = New(CodeInvalidTransition, "transition is not allowed from current PR status")
+	ErrMergeBlocked       = New(CodeMergeBlocked, "merge requirements not met")
+	ErrNotEnoughReviewers = New(CodeNotEnoughReviewers, "not enough active reviewers available")
+	ErrUnauthorized       = New(CodeUnauthorized, "authentication required")
+	ErrForbidden          = New(CodeForbidden, "not allowed for this role")
+)
@@ -0,0 +1,36 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorIs(t *testing.T) {
+	wrapped := fmt.Errorf("get team: %w", ErrTeamNotFound)
+
+	assert.ErrorIs(t, wrapped, ErrTeamNotFound)
+	assert.ErrorIs(t, wrapped, ErrNotFound)
+	assert.NotErrorIs(t, wrapped, ErrUserNotFound)
+	assert.NotErrorIs(t, wrapped, ErrPRExists)
+	assert.NotErrorIs(t, errors.New("team not found"), ErrTeamNotFound)
+}
+
+func TestWithDetails(t *testing.T) {
+	err := ErrMergeBlocked.WithDetails("failed_rules", []string{"HAS_REVIEWER"})
+
+	assert.ErrorIs(t, err, ErrMergeBlocked)
+	assert.Equal(t, []string{"HAS_REVIEWER"}, err.Details["failed_rules"])
+	assert.Nil(t, ErrMergeBlocked.Details)
+}
+
+func TestFrom(t *testing.T) {
+	appErr, ok := From(fmt.Errorf("wrap: %w", ErrPRMerged))
+	assert.True(t, ok)
+	assert.Equal(t, CodePRMerged, appErr.Code)
+
+	_, ok = From(errors.New("plain"))
+	assert.False(t, ok)
+}
@@ -0,0 +1,144 @@
+// Package auth проверяет подписанные bearer-токены и статические API-ключи
+// сервисов и определяет, от чьего имени выполняется запрос.
+package auth
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"reviewtask/apperr"
+)
+
+// Role — роль вызывающего API
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleTeamLead Role = "team-lead"
+	RoleMember   Role = "member"
+	RoleBot      Role = "bot"
+)
+
+func ParseRole(value string) (Role, error) {
+	switch role := Role(value); role {
+	case RoleAdmin, RoleTeamLead, RoleMember, RoleBot:
+		return role, nil
+	}
+	return "", fmt.Errorf("unknown role %q", value)
+}
+
+// Principal — аутентифицированный вызывающий. UserID задан для токенов
+// пользователей; у API-ключей сервисов он пустой.
+type Principal struct {
+	Subject string
+	UserID  string
+	Role    Role
+}
+
+func (p *Principal) HasRole(roles ...Role) bool {
+	for _, role := range roles {
+		if p.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// Authenticator проверяет учётные данные запроса
+type Authenticator struct {
+	secret   []byte
+	apiKeys  []apiKey
+	disabled bool
+	now      func() time.Time
+}
+
+type apiKey struct {
+	key       []byte
+	principal Principal
+}
+
+// NewAuthenticator создаёт проверку токенов, подписанных secret, и ключей apiKeys
+// в формате name:role:key через запятую. Пустой secret отключает токены.
+func NewAuthenticator(secret, apiKeys string) (*Authenticator, error) {
+	a := &Authenticator{secret: []byte(secret), now: time.Now}
+
+	for _, entry := range strings.Split(apiKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("API key must be name:role:key, got %q", entry)
+		}
+		role, err := ParseRole(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("API key %q: %w", parts[0], err)
+		}
+
+		a.apiKeys = append(a.apiKeys, apiKey{
+			key:       []byte(parts[2]),
+			principal: Principal{Subject: "apikey:" + parts[0], Role: role},
+		})
+	}
+
+	if len(a.secret) == 0 && len(a.apiKeys) == 0 {
+		return nil, fmt.Errorf("no token secret or API keys configured")
+	}
+	return a, nil
+}
+
+// Disabled возвращает аутентификатор, пропускающий все запросы с ролью admin.
+// Только для локальной разработки.
+func Disabled() *Authenticator {
+	return &Authenticator{disabled: true, now: time.Now}
+}
+
+// FromEnv настраивает аутентификацию по AUTH_TOKEN_SECRET и AUTH_API_KEYS.
+// AUTH_DISABLED=true отключает проверку.
+func FromEnv() (*Authenticator, error) {
+	if os.Getenv("AUTH_DISABLED") == "true" {
+		return Disabled(), nil
+	}
+	return NewAuthenticator(os.Getenv("AUTH_TOKEN_SECRET"), os.Getenv("AUTH_API_KEYS"))
+}
+
+func (a *Authenticator) IsDisabled() bool {
+	return a.disabled
+}
+
+// Authenticate проверяет заголовок Authorization: Bearer <token> или X-API-Key
+func (a *Authenticator) Authenticate(authorization, key string) (*Principal, error) {
+	if a.disabled {
+		return &Principal{Subject: "anonymous", Role: RoleAdmin}, nil
+	}
+
+	if key != "" {
+		for _, k := range a.apiKeys {
+			if subtle.ConstantTimeCompare(k.key, []byte(key)) == 1 {
+				principal := k.principal
+				return &principal, nil
+			}
+		}
+		return nil, apperr.New(apperr.CodeUnauthorized, "invalid API key")
+	}
+
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return nil, apperr.ErrUnauthorized
+	}
+	if len(a.secret) == 0 {
+		return nil, apperr.New(apperr.CodeUnauthorized, "bearer tokens are not accepted")
+	}
+
+	claims, err := VerifyToken(a.secret, token, a.now())
+	if err != nil {
+		return nil, apperr.New(apperr.CodeUnauthorized, err.Error())
+	}
+
+	return &Principal{Subject: "user:" + claims.Subject, UserID: claims.Subject, Role: claims.Role}, nil
+}
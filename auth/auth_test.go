@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"reviewtask/apperr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testSecret = []byte("test-secret")
+	testNow    = time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func TestTokenRoundTrip(t *testing.T) {
+	token, err := IssueToken(testSecret, "u1", RoleTeamLead, time.Hour, testNow)
+	require.NoError(t, err)
+
+	claims, err := VerifyToken(testSecret, token, testNow.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, "u1", claims.Subject)
+	assert.Equal(t, RoleTeamLead, claims.Role)
+	assert.Equal(t, testNow.Add(time.Hour).Unix(), claims.ExpiresAt)
+}
+
+func TestVerifyTokenRejects(t *testing.T) {
+	token, err := IssueToken(testSecret, "u1", RoleMember, time.Hour, testNow)
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+
+	forged := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"u1","role":"admin","iat":0,"exp":9999999999}`))
+	noneHeader := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+
+	tests := []struct {
+		name   string
+		token  string
+		secret []byte
+		now    time.Time
+		want   error
+	}{
+		{name: "expired", token: token, secret: testSecret, now: testNow.Add(time.Hour), want: errTokenExpired},
+		{name: "wrong secret", token: token, secret: []byte("other"), now: testNow, want: errBadSignature},
+		{name: "tampered payload", token: parts[0] + "." + forged + "." + parts[2], secret: testSecret, now: testNow, want: errBadSignature},
+		{name: "alg none", token: noneHeader + "." + forged + ".", secret: testSecret, now: testNow, want: errMalformedToken},
+		{name: "not a jwt", token: "garbage", secret: testSecret, now: testNow, want: errMalformedToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := VerifyToken(tt.secret, tt.token, tt.now)
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestIssueTokenValidation(t *testing.T) {
+	_, err := IssueToken(nil, "u1", RoleMember, time.Hour, testNow)
+	assert.Error(t, err)
+
+	_, err = IssueToken(testSecret, "", RoleMember, time.Hour, testNow)
+	assert.Error(t, err)
+
+	_, err = IssueToken(testSecret, "u1", Role("root"), time.Hour, testNow)
+	assert.Error(t, err)
+}
+
+func TestNewAuthenticatorConfig(t *testing.T) {
+	_, err := NewAuthenticator("", "")
+	assert.Error(t, err, "auth must not silently accept everything")
+
+	_, err = NewAuthenticator("", "ci:root:key")
+	assert.Error(t, err)
+
+	_, err = NewAuthenticator("", "ci:bot")
+	assert.Error(t, err)
+
+	a, err := NewAuthenticator("", " ci:bot:k1 , ops:admin:k2 ")
+	require.NoError(t, err)
+	assert.Len(t, a.apiKeys, 2)
+}
+
+func TestAuthenticate(t *testing.T) {
+	a, err := NewAuthenticator(string(testSecret), "ci:bot:bot-key")
+	require.NoError(t, err)
+	a.now = func() time.Time { return testNow }
+
+	token, err := IssueToken(testSecret, "u7", RoleMember, time.Hour, testNow)
+	require.NoError(t, err)
+
+	principal, err := a.Authenticate("Bearer "+token, "")
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "user:u7", UserID: "u7", Role: RoleMember}, principal)
+
+	principal, err = a.Authenticate("", "bot-key")
+	require.NoError(t, err)
+	assert.Equal(t, &Principal{Subject: "apikey:ci", Role: RoleBot}, principal)
+
+	for name, headers := range map[string][2]string{
+		"no credentials": {"", ""},
+		"wrong key":      {"", "other-key"},
+		"basic auth":     {"Basic dTc6cGFzcw==", ""},
+		"bad token":      {"Bearer " + token + "x", ""},
+	} {
+		_, err := a.Authenticate(headers[0], headers[1])
+		assert.ErrorIs(t, err, apperr.New(apperr.CodeUnauthorized, ""), name)
+	}
+}
+
+func TestAuthenticateDisabled(t *testing.T) {
+	principal, err := Disabled().Authenticate("", "")
+	require.NoError(t, err)
+	assert.True(t, principal.HasRole(RoleAdmin))
+}
@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Claims — содержимое токена: пользователь, его роль и срок действия
+type Claims struct {
+	Subject   string `json:"sub"`
+	Role      Role   `json:"role"`
+	IssuedAt  int64  `json:"iat"`
+	ExpiresAt int64  `json:"exp"`
+}
+
+// Токены — JWT с подписью HS256: проверяются без обращения к БД и сторонним сервисам
+var tokenHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+var (
+	errMalformedToken = errors.New("malformed token")
+	errBadSignature   = errors.New("invalid token signature")
+	errTokenExpired   = errors.New("token expired")
+)
+
+// IssueToken выпускает токен пользователя userID с ролью role на срок ttl
+func IssueToken(secret []byte, userID string, role Role, ttl time.Duration, now time.Time) (string, error) {
+	if len(secret) == 0 {
+		return "", errors.New("token secret is empty")
+	}
+	if userID == "" {
+		return "", errors.New("user id is required")
+	}
+	if _, err := ParseRole(string(role)); err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(Claims{
+		Subject:   userID,
+		Role:      role,
+		IssuedAt:  now.Unix(),
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := tokenHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	return unsigned + "." + sign(secret, unsigned), nil
+}
+
+// VerifyToken проверяет подпись и срок действия токена
+func VerifyToken(secret []byte, token string, now time.Time) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errMalformedToken
+	}
+
+	// Заголовок сравнивается целиком: другие алгоритмы, включая "none", не принимаются
+	if parts[0] != tokenHeader {
+		return nil, errMalformedToken
+	}
+
+	expected := sign(secret, parts[0]+"."+parts[1])
+	if !hmac.Equal([]byte(expected), []byte(parts[2])) {
+		return nil, errBadSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errMalformedToken
+	}
+
+	var claims Claims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.Subject == "" {
+		return nil, errMalformedToken
+	}
+	if _, err := ParseRole(string(claims.Role)); err != nil {
+		return nil, errMalformedToken
+	}
+	if claims.ExpiresAt == 0 || now.Unix() >= claims.ExpiresAt {
+		return nil, errTokenExpired
+	}
+
+	return &claims, nil
+}
+
+func sign(secret []byte, data string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(data))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
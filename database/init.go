@@ -13,6 +13,17 @@ import (
 )
 
 func InitDB() *repo.Repository {
+	db := OpenDB()
+
+	if err := PrepareSchema(db); err != nil {
+		log.Fatal("Database schema is not ready:", err)
+	}
+
+	return repo.NewRepository(db)
+}
+
+// OpenDB подключается к БД и дожидается её готовности
+func OpenDB() *sql.DB {
 	db, err := sql.Open("postgres", getDBConnectionString())
 	if err != nil {
 		log.Fatal("Failed to connect to database:", err)
@@ -22,7 +33,38 @@ func InitDB() *repo.Repository {
 		log.Fatal("Database not ready:", err)
 	}
 
-	return repo.NewRepository(db)
+	return db
+}
+
+// PrepareSchema проверяет версию схемы при старте сервиса. Схема новее
+// бинарника — ошибка; неприменённые миграции применяются, если не задан
+// AUTO_MIGRATE=false, иначе старт тоже прерывается.
+func PrepareSchema(db *sql.DB) error {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	if err := migrator.CheckVersion(); err != nil {
+		return err
+	}
+
+	if getEnv("AUTO_MIGRATE", "true") != "false" {
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			log.Printf("Applied migration %03d-%s", m.Version, m.Name)
+		}
+		return err
+	}
+
+	current, err := migrator.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	if current < migrator.LatestVersion() {
+		return fmt.Errorf("schema version %d is older than %d; run `migrate up`", current, migrator.LatestVersion())
+	}
+	return nil
 }
 
 func getDBConnectionString() string {
@@ -0,0 +1,409 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"reviewtask/migrations"
+)
+
+// migrationLockKey — ключ advisory-блокировки PostgreSQL, под которой
+// применяются миграции: несколько экземпляров сервиса не мигрируют схему одновременно
+const migrationLockKey = 7240318
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)-([a-z0-9-]+)\.(up|down)\.sql$`)
+
+// ErrSchemaTooNew — схема БД новее последней миграции, известной бинарнику
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary supports")
+
+// ErrUntrackedSchema — таблицы уже созданы, версия схемы не записана, и схема
+// не совпадает с исходной (версия 1), которую мигратор распознаёт сам
+var ErrUntrackedSchema = errors.New("database schema exists but schema_migrations is empty and the schema is not the baseline; run `migrate force <version>` with the last version the schema matches")
+
+// Migration — версия схемы и SQL для перехода на неё и отката
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// MigrationStatus — состояние миграции в БД. Known == false означает версию,
+// применённую более новым бинарником.
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	Known     bool
+	AppliedAt *time.Time
+}
+
+// LoadMigrations читает миграции из fsys. У каждой версии должны быть
+// оба файла — up и down, версии идут подряд начиная с 1.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileRe.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %03d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %03d-%s must have both up and down files", m.Version, m.Name)
+		}
+		result = append(result, *m)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Version < result[j].Version
+	})
+
+	for i, m := range result {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be sequential: expected %03d, got %03d", i+1, m.Version)
+		}
+	}
+
+	return result, nil
+}
+
+// Migrator применяет и откатывает миграции, записывая версии в schema_migrations
+type Migrator struct {
+	db         *sql.DB
+	migrations []Migration
+}
+
+// NewMigrator создаёт мигратор со встроенными в бинарник миграциями
+func NewMigrator(db *sql.DB) (*Migrator, error) {
+	list, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		return nil, fmt.Errorf("load migrations: %w", err)
+	}
+	return &Migrator{db: db, migrations: list}, nil
+}
+
+// LatestVersion — последняя версия схемы, известная бинарнику
+func (m *Migrator) LatestVersion() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// CurrentVersion возвращает последнюю применённую версию (0 — миграций не было).
+// Схему не меняет: без schema_migrations версия — 0.
+func (m *Migrator) CurrentVersion() (int, error) {
+	tracked, err := m.versionTableExists()
+	if err != nil || !tracked {
+		return 0, err
+	}
+
+	var version int
+	err = m.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// CheckVersion проверяет, что сервис понимает схему БД
+func (m *Migrator) CheckVersion() error {
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return err
+	}
+	return checkSchemaVersion(current, m.LatestVersion())
+}
+
+func checkSchemaVersion(current, latest int) error {
+	if current > latest {
+		return fmt.Errorf("%w: schema version %d, latest known %d", ErrSchemaTooNew, current, latest)
+	}
+	return nil
+}
+
+// Up применяет все неприменённые миграции, каждую в своей транзакции,
+// и возвращает применённые
+func (m *Migrator) Up() ([]Migration, error) {
+	if err := m.ensureVersionTable(); err != nil {
+		return nil, err
+	}
+
+	current, err := m.CurrentVersion()
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSchemaVersion(current, m.LatestVersion()); err != nil {
+		return nil, err
+	}
+	if current == 0 {
+		if current, err = m.adoptUntrackedSchema(); err != nil {
+			return nil, err
+		}
+	}
+
+	applied := []Migration{}
+	for _, migration := range m.migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		done, err := m.apply(migration)
+		if err != nil {
+			return applied, fmt.Errorf("migration %03d-%s: %w", migration.Version, migration.Name, err)
+		}
+		if done {
+			applied = append(applied, migration)
+		}
+	}
+
+	return applied, nil
+}
+
+// apply применяет миграцию под блокировкой; false — её уже применил другой процесс
+func (m *Migrator) apply(migration Migration) (bool, error) {
+	var done bool
+	err := m.withLock(func(tx *sql.Tx) error {
+		var exists bool
+		err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version = $1)`, migration.Version).Scan(&exists)
+		if err != nil || exists {
+			return err
+		}
+
+		if _, err := tx.Exec(migration.Up); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+			migration.Version, migration.Name,
+		); err != nil {
+			return err
+		}
+
+		done = true
+		return nil
+	})
+	return done, err
+}
+
+// Down откатывает steps последних применённых миграций и возвращает откаченные
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	reverted := []Migration{}
+	for i := 0; i < steps; i++ {
+		var migration *Migration
+		err := m.withLock(func(tx *sql.Tx) error {
+			var version int
+			err := tx.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+			if err != nil || version == 0 {
+				return err
+			}
+
+			migration = m.find(version)
+			if migration == nil {
+				return fmt.Errorf("%w: cannot revert unknown version %d", ErrSchemaTooNew, version)
+			}
+
+			if _, err := tx.Exec(migration.Down); err != nil {
+				return fmt.Errorf("migration %03d-%s: %w", migration.Version, migration.Name, err)
+			}
+			_, err = tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, version)
+			return err
+		})
+		if err != nil {
+			return reverted, err
+		}
+		if migration == nil {
+			break
+		}
+		reverted = append(reverted, *migration)
+	}
+
+	return reverted, nil
+}
+
+// Force записывает версии 1..version применёнными без выполнения SQL.
+// Нужна для БД, созданных до появления schema_migrations.
+func (m *Migrator) Force(version int) error {
+	if version < 0 || version > m.LatestVersion() {
+		return fmt.Errorf("version must be between 0 and %d", m.LatestVersion())
+	}
+
+	if err := m.ensureVersionTable(); err != nil {
+		return err
+	}
+
+	return m.withLock(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM schema_migrations`); err != nil {
+			return err
+		}
+		for _, migration := range m.migrations[:version] {
+			if _, err := tx.Exec(
+				`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`,
+				migration.Version, migration.Name,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Status возвращает все известные миграции и применённые версии, неизвестные бинарнику.
+// Схему не меняет: без schema_migrations все миграции считаются неприменёнными.
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions()
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []MigrationStatus{}
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name, Known: true}
+		if a, ok := applied[migration.Version]; ok {
+			status.AppliedAt = a.AppliedAt
+			delete(applied, migration.Version)
+		}
+		statuses = append(statuses, status)
+	}
+	for _, status := range applied {
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Version < statuses[j].Version
+	})
+
+	return statuses, nil
+}
+
+// appliedVersions читает записи schema_migrations по версиям
+func (m *Migrator) appliedVersions() (map[int]MigrationStatus, error) {
+	applied := make(map[int]MigrationStatus)
+	tracked, err := m.versionTableExists()
+	if err != nil || !tracked {
+		return applied, err
+	}
+
+	rows, err := m.db.Query(`SELECT version, name, applied_at FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status MigrationStatus
+		var appliedAt time.Time
+		if err := rows.Scan(&status.Version, &status.Name, &appliedAt); err != nil {
+			return nil, err
+		}
+		status.AppliedAt = &appliedAt
+		applied[status.Version] = status
+	}
+	return applied, rows.Err()
+}
+
+func (m *Migrator) find(version int) *Migration {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i]
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) versionTableExists() (bool, error) {
+	var exists bool
+	err := m.db.QueryRow(`SELECT to_regclass('schema_migrations') IS NOT NULL`).Scan(&exists)
+	return exists, err
+}
+
+func (m *Migrator) ensureVersionTable() error {
+	return m.withLock(func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+      CREATE TABLE IF NOT EXISTS schema_migrations (
+        version INTEGER PRIMARY KEY,
+        name VARCHAR(255) NOT NULL,
+        applied_at TIMESTAMP NOT NULL DEFAULT NOW()
+      )`)
+		return err
+	})
+}
+
+// adoptUntrackedSchema разбирается со схемой, созданной без мигратора, и возвращает
+// её версию. Пустая БД — 0. Исходная схема (teams без pr_reviewers из 002) — это
+// версия 1: она записывается, и Up применяет 002 и дальше с переносом данных.
+// Иную схему без записанной версии угадывать нельзя — ErrUntrackedSchema.
+func (m *Migrator) adoptUntrackedSchema() (int, error) {
+	var hasTeams, hasReviewers bool
+	err := m.db.QueryRow(
+		`SELECT to_regclass('teams') IS NOT NULL, to_regclass('pr_reviewers') IS NOT NULL`,
+	).Scan(&hasTeams, &hasReviewers)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case !hasTeams:
+		return 0, nil
+	case hasReviewers:
+		return 0, ErrUntrackedSchema
+	}
+
+	err = m.withLock(func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO schema_migrations (version, name) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			m.migrations[0].Version, m.migrations[0].Name,
+		)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return m.migrations[0].Version, nil
+}
+
+// withLock выполняет fn в транзакции под advisory-блокировкой миграций
+func (m *Migrator) withLock(fn func(tx *sql.Tx) error) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockKey); err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
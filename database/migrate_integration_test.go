@@ -0,0 +1,162 @@
+//go:build integration
+
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSchema открывает TEST_DATABASE_URL с пустой схемой в search_path:
+// мигратор работает с ней, не трогая схему, которую используют другие тесты
+func newTestSchema(t *testing.T) (*sql.DB, *Migrator) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	// search_path задаётся на соединение, поэтому соединение одно
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	schema := fmt.Sprintf("migrate_test_%d", time.Now().UnixNano())
+	_, err = db.Exec("CREATE SCHEMA " + schema)
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Exec("DROP SCHEMA " + schema + " CASCADE") })
+	_, err = db.Exec("SET search_path TO " + schema)
+	require.NoError(t, err)
+
+	migrator, err := NewMigrator(db)
+	require.NoError(t, err)
+	return db, migrator
+}
+
+func tableExists(t *testing.T, db *sql.DB, table string) bool {
+	t.Helper()
+	var exists bool
+	require.NoError(t, db.QueryRow("SELECT to_regclass($1) IS NOT NULL", table).Scan(&exists))
+	return exists
+}
+
+func TestMigratorStatusIsReadOnly(t *testing.T) {
+	db, migrator := newTestSchema(t)
+
+	current, err := migrator.CurrentVersion()
+	require.NoError(t, err)
+	assert.Zero(t, current)
+	require.NoError(t, migrator.CheckVersion())
+
+	statuses, err := migrator.Status()
+	require.NoError(t, err)
+	require.Len(t, statuses, migrator.LatestVersion())
+	for _, status := range statuses {
+		assert.Nil(t, status.AppliedAt, "migration %03d", status.Version)
+	}
+
+	assert.False(t, tableExists(t, db, "schema_migrations"))
+}
+
+func TestMigratorAdoptsBaselineSchema(t *testing.T) {
+	db, migrator := newTestSchema(t)
+
+	// БД до появления мигратора: исходная схема без schema_migrations
+	_, err := db.Exec(migrator.migrations[0].Up)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO teams (team_name) VALUES ('backend')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO users (user_id, username, team_name) VALUES ('u1', 'u1', 'backend'), ('u2', 'u2', 'backend')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, assigned_reviewers)
+		VALUES ('pr-1', 'Add search', 'u1', 'u2')`)
+	require.NoError(t, err)
+
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	require.NotEmpty(t, applied)
+	assert.Equal(t, 2, applied[0].Version)
+
+	current, err := migrator.CurrentVersion()
+	require.NoError(t, err)
+	assert.Equal(t, migrator.LatestVersion(), current)
+
+	var reviewer string
+	require.NoError(t, db.QueryRow(`SELECT user_id FROM pr_reviewers WHERE pull_request_id = 'pr-1'`).Scan(&reviewer))
+	assert.Equal(t, "u2", reviewer)
+	assert.True(t, tableExists(t, db, "user_absences"))
+	assert.True(t, tableExists(t, db, "outbox_events"))
+}
+
+func TestPRReviewersBackfill(t *testing.T) {
+	db, migrator := newTestSchema(t)
+	require.NoError(t, migrator.ensureVersionTable())
+	_, err := migrator.apply(migrator.migrations[0])
+	require.NoError(t, err)
+
+	// До 002 ревьюеры хранились строкой через запятую
+	_, err = db.Exec(`INSERT INTO teams (team_name) VALUES ('backend')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO users (user_id, username, team_name)
+		VALUES ('u1', 'u1', 'backend'), ('u2', 'u2', 'backend'), ('u3', 'u3', 'backend')`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, assigned_reviewers)
+		VALUES ('pr-0', 'None', 'u1', ''), ('pr-1', 'One', 'u1', 'u2'), ('pr-2', 'Two', 'u1', 'u2,u3')`)
+	require.NoError(t, err)
+
+	_, err = migrator.apply(migrator.migrations[1])
+	require.NoError(t, err)
+
+	reviewers := func(prID string) []string {
+		t.Helper()
+		rows, err := db.Query(`SELECT user_id FROM pr_reviewers WHERE pull_request_id = $1 ORDER BY user_id`, prID)
+		require.NoError(t, err)
+		defer rows.Close()
+
+		ids := []string{}
+		for rows.Next() {
+			var id string
+			require.NoError(t, rows.Scan(&id))
+			ids = append(ids, id)
+		}
+		require.NoError(t, rows.Err())
+		return ids
+	}
+	assert.Empty(t, reviewers("pr-0"))
+	assert.Equal(t, []string{"u2"}, reviewers("pr-1"))
+	assert.Equal(t, []string{"u2", "u3"}, reviewers("pr-2"))
+
+	// Откат возвращает строковый формат
+	_, err = db.Exec(migrator.migrations[1].Down)
+	require.NoError(t, err)
+	for prID, want := range map[string]string{"pr-0": "", "pr-1": "u2", "pr-2": "u2,u3"} {
+		var got string
+		require.NoError(t, db.QueryRow(`SELECT assigned_reviewers FROM pull_requests WHERE pull_request_id = $1`, prID).Scan(&got))
+		assert.Equal(t, want, got, prID)
+	}
+}
+
+func TestMigratorRejectsUnknownUntrackedSchema(t *testing.T) {
+	db, migrator := newTestSchema(t)
+
+	// Схема новее исходной, но без записанной версии: её версию не угадать
+	for _, migration := range migrator.migrations[:2] {
+		_, err := db.Exec(migration.Up)
+		require.NoError(t, err)
+	}
+
+	_, err := migrator.Up()
+	assert.ErrorIs(t, err, ErrUntrackedSchema)
+
+	require.NoError(t, migrator.Force(2))
+	applied, err := migrator.Up()
+	require.NoError(t, err)
+	assert.Equal(t, 3, applied[0].Version)
+}
@@ -0,0 +1,83 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"reviewtask/migrations"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002-add-index.up.sql":   {Data: []byte("CREATE INDEX i ON t(id);")},
+		"002-add-index.down.sql": {Data: []byte("DROP INDEX i;")},
+		"001-init.up.sql":        {Data: []byte("CREATE TABLE t (id INT);")},
+		"001-init.down.sql":      {Data: []byte("DROP TABLE t;")},
+		"README.md":              {Data: []byte("ignored")},
+	}
+
+	list, err := LoadMigrations(fsys)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	assert.Equal(t, Migration{Version: 1, Name: "init", Up: "CREATE TABLE t (id INT);", Down: "DROP TABLE t;"}, list[0])
+	assert.Equal(t, 2, list[1].Version)
+	assert.Equal(t, "add-index", list[1].Name)
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "missing down file",
+			fsys: fstest.MapFS{"001-init.up.sql": {Data: []byte("SELECT 1;")}},
+		},
+		{
+			name: "gap in versions",
+			fsys: fstest.MapFS{
+				"001-init.up.sql":    {Data: []byte("SELECT 1;")},
+				"001-init.down.sql":  {Data: []byte("SELECT 1;")},
+				"003-later.up.sql":   {Data: []byte("SELECT 1;")},
+				"003-later.down.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+		{
+			name: "conflicting names",
+			fsys: fstest.MapFS{
+				"001-init.up.sql":  {Data: []byte("SELECT 1;")},
+				"001-other.up.sql": {Data: []byte("SELECT 1;")},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadMigrations(tt.fsys)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	list, err := LoadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, list)
+
+	assert.Equal(t, "init", list[0].Name)
+	for _, m := range list {
+		// Миграции применяются к существующим данным и не должны их удалять
+		assert.NotContains(t, strings.ToUpper(m.Up), "DROP TABLE", "migration %03d-%s", m.Version, m.Name)
+	}
+}
+
+func TestCheckSchemaVersion(t *testing.T) {
+	assert.NoError(t, checkSchemaVersion(0, 12))
+	assert.NoError(t, checkSchemaVersion(12, 12))
+	assert.ErrorIs(t, checkSchemaVersion(13, 12), ErrSchemaTooNew)
+}
@@ -25,6 +25,8 @@ func InitTestData(repo *repo.Repository) {
 	// Создаем тестовую команду frontend
 	frontendTeam := &models.Team{
 		TeamName: "frontend",
+		// Команда маленькая — недостающих ревьюеров добираем из backend
+		PartnerTeams: []string{"backend"},
 		Members: []models.TeamMember{
 			{UserID: "u5", Username: "eve", IsActive: true},
 			{UserID: "u6", Username: "frank", IsActive: true},
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"reviewtask/outbox"
+	"reviewtask/repo"
+	"reviewtask/webhook"
+)
+
+// startEventPipeline запускает публикацию событий из outbox и доставку вебхуков
+func startEventPipeline(ctx context.Context, store *repo.Repository) error {
+	spec := os.Getenv("OUTBOX_SINKS")
+	if spec == "" {
+		spec = "webhook"
+	}
+
+	sinks, err := buildSinks(spec, store)
+	if err != nil {
+		return err
+	}
+
+	go outbox.NewDispatcher(store, outbox.DefaultConfig(), sinks...).Run(ctx)
+	go webhook.NewWorker(store, webhook.DefaultConfig()).Run(ctx)
+	return nil
+}
+
+// buildSinks разбирает список приёмников через запятую: webhook, stdout, file:PATH
+func buildSinks(spec string, store repo.Store) ([]outbox.Sink, error) {
+	sinks := []outbox.Sink{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		switch {
+		case name == "":
+			continue
+		case name == "webhook":
+			sinks = append(sinks, webhook.NewSink(store))
+		case name == "stdout":
+			sinks = append(sinks, outbox.NewStdoutSink())
+		case strings.HasPrefix(name, "file:"):
+			sink, err := outbox.NewFileSink(strings.TrimPrefix(name, "file:"))
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"errors"
+	"reviewtask/apperr"
+	"reviewtask/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+const principalKey = "principal"
+
+// Authenticate проверяет токен или API-ключ запроса и сохраняет вызывающего в контексте
+func Authenticate(authenticator *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, err := authenticator.Authenticate(c.GetHeader("Authorization"), c.GetHeader("X-API-Key"))
+		if err != nil {
+			c.Error(err)
+			c.Abort()
+			return
+		}
+
+		c.Set(principalKey, principal)
+		c.Next()
+	}
+}
+
+// RequireRoles пропускает только вызывающих с одной из ролей
+func RequireRoles(roles ...auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !currentPrincipal(c).HasRole(roles...) {
+			c.Error(apperr.ErrForbidden)
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+func currentPrincipal(c *gin.Context) *auth.Principal {
+	if p, ok := c.Get(principalKey); ok {
+		return p.(*auth.Principal)
+	}
+	return &auth.Principal{}
+}
+
+// actorID — от чьего имени выполняется действие для журнала событий.
+// Пользователь всегда действует от своего имени; сервис по API-ключу
+// может передать actor_id в запросе.
+func actorID(c *gin.Context, requested string) string {
+	principal := currentPrincipal(c)
+	switch {
+	case principal.UserID != "":
+		return principal.UserID
+	case requested != "":
+		return requested
+	}
+	return principal.Subject
+}
+
+// Правила доступа к изменяющим эндпоинтам:
+//   - admin — без ограничений;
+//   - team-lead — настройки и состав своей команды, PR её авторов;
+//   - member — свои PR, свои ревью и свои отсутствия;
+//   - bot — создание PR и синхронизация составов команд.
+
+// authorizeTeam пропускает администратора и лида команды teamName
+func (app *App) authorizeTeam(c *gin.Context, teamName string) error {
+	principal := currentPrincipal(c)
+	if principal.HasRole(auth.RoleAdmin) {
+		return nil
+	}
+	if principal.HasRole(auth.RoleTeamLead) && app.principalTeam(principal) == teamName && teamName != "" {
+		return nil
+	}
+	return apperr.ErrForbidden
+}
+
+// authorizeUser пропускает самого пользователя, лида его команды и администратора
+func (app *App) authorizeUser(c *gin.Context, userID string) error {
+	principal := currentPrincipal(c)
+	if principal.UserID != "" && principal.UserID == userID && !principal.HasRole(auth.RoleBot) {
+		return nil
+	}
+	return app.authorizeTeamOf(c, userID)
+}
+
+// authorizeTeamOf пропускает администратора и лида команды пользователя userID
+func (app *App) authorizeTeamOf(c *gin.Context, userID string) error {
+	if currentPrincipal(c).HasRole(auth.RoleAdmin) {
+		return nil
+	}
+
+	user, err := app.Service.GetUser(userID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrUserNotFound) {
+			return apperr.ErrForbidden
+		}
+		return err
+	}
+	return app.authorizeTeam(c, user.TeamName)
+}
+
+// authorizePRAuthor пропускает администратора, автора PR и, если allowLead,
+// лида команды автора
+func (app *App) authorizePRAuthor(c *gin.Context, prID string, allowLead bool) error {
+	principal := currentPrincipal(c)
+	if principal.HasRole(auth.RoleAdmin) {
+		return nil
+	}
+
+	pr, err := app.Service.GetPR(prID)
+	if err != nil {
+		return err
+	}
+	if principal.UserID != "" && principal.UserID == pr.AuthorID && !principal.HasRole(auth.RoleBot) {
+		return nil
+	}
+	if allowLead {
+		return app.authorizeTeamOf(c, pr.AuthorID)
+	}
+	return apperr.ErrForbidden
+}
+
+// principalTeam — команда пользователя, от имени которого пришёл запрос
+func (app *App) principalTeam(principal *auth.Principal) string {
+	if principal.UserID == "" {
+		return ""
+	}
+	user, err := app.Service.GetUser(principal.UserID)
+	if err != nil {
+		return ""
+	}
+	return user.TeamName
+}
+
+// authorizeSelf пропускает администратора и самого пользователя userID
+func authorizeSelf(c *gin.Context, userID string) error {
+	principal := currentPrincipal(c)
+	if principal.HasRole(auth.RoleAdmin) {
+		return nil
+	}
+	if principal.UserID != "" && principal.UserID == userID && !principal.HasRole(auth.RoleBot) {
+		return nil
+	}
+	return apperr.ErrForbidden
+}
+
+// authorizePRCreate пропускает администратора, бота и автора, создающего свой PR
+func authorizePRCreate(c *gin.Context, authorID string) error {
+	if currentPrincipal(c).HasRole(auth.RoleBot) {
+		return nil
+	}
+	return authorizeSelf(c, authorID)
+}
+
+// authorizeReassign пропускает ревьюера, отдающего своё ревью, а также тех,
+// кто может управлять PR: администратора, автора и лида его команды
+func (app *App) authorizeReassign(c *gin.Context, prID, reviewerID string) error {
+	if err := authorizeSelf(c, reviewerID); err == nil {
+		return nil
+	}
+	return app.authorizePRAuthor(c, prID, true)
+}
+
+// authorizeDeactivation пропускает администратора и лида, если команда
+// и все пользователи принадлежат его команде
+func (app *App) authorizeDeactivation(c *gin.Context, userIDs []string, teamName string) error {
+	if currentPrincipal(c).HasRole(auth.RoleAdmin) {
+		return nil
+	}
+	if teamName == "" && len(userIDs) == 0 {
+		return apperr.ErrForbidden
+	}
+
+	if teamName != "" {
+		if err := app.authorizeTeam(c, teamName); err != nil {
+			return err
+		}
+	}
+	for _, userID := range userIDs {
+		if err := app.authorizeTeamOf(c, userID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reviewtask/auth"
+	"reviewtask/models"
+	"reviewtask/repo"
+	"reviewtask/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAuthSecret = "handlers-test-secret"
+
+// authTestServer — роутер приложения (RegisterRoutes) с аутентификацией поверх хранилища в памяти.
+// Команды: backend (lead, u1, u2, u3) и frontend (f1, f2, f3).
+type authTestServer struct {
+	t      *testing.T
+	app    *App
+	router *gin.Engine
+}
+
+func newAuthTestServer(t *testing.T) *authTestServer {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	svc := service.NewReviewService(repo.NewMemoryStore())
+	for team, ids := range map[string][]string{
+		"backend":  {"lead", "u1", "u2", "u3"},
+		"frontend": {"f1", "f2", "f3"},
+	} {
+		members := []models.TeamMember{}
+		for _, id := range ids {
+			members = append(members, models.TeamMember{UserID: id, Username: id, IsActive: true})
+		}
+		require.NoError(t, svc.CreateTeam(&models.Team{TeamName: team, Members: members}))
+	}
+
+	authenticator, err := auth.NewAuthenticator(testAuthSecret, "ci:bot:bot-key")
+	require.NoError(t, err)
+
+	app := &App{Service: svc}
+	r := gin.New()
+	r.Use(ErrorHandler())
+	app.RegisterRoutes(r.Group("/", Authenticate(authenticator)))
+
+	return &authTestServer{t: t, app: app, router: r}
+}
+
+func (s *authTestServer) token(userID string, role auth.Role) string {
+	s.t.Helper()
+	token, err := auth.IssueToken([]byte(testAuthSecret), userID, role, time.Hour, time.Now())
+	require.NoError(s.t, err)
+	return "Bearer " + token
+}
+
+// do выполняет запрос с заголовком Authorization или, для "key:...", X-API-Key
+func (s *authTestServer) do(method, path, credentials string, body interface{}) *httptest.ResponseRecorder {
+	s.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		require.NoError(s.t, json.NewEncoder(&payload).Encode(body))
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	if key, ok := strings.CutPrefix(credentials, "key:"); ok {
+		req.Header.Set("X-API-Key", key)
+	} else if credentials != "" {
+		req.Header.Set("Authorization", credentials)
+	}
+
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, req)
+	return w
+}
+
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body errorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	code, _ := body.Error["code"].(string)
+	return code
+}
+
+func TestAuthenticateRejectsMissingCredentials(t *testing.T) {
+	s := newAuthTestServer(t)
+
+	w := s.do(http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", "", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "UNAUTHORIZED", errorCode(t, w))
+
+	w = s.do(http.MethodGet, "/pullRequest/get?pull_request_id=pr-1", "key:wrong", nil)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestRoleRestrictions(t *testing.T) {
+	s := newAuthTestServer(t)
+
+	// Бот создаёт PR за любого автора, но не мержит
+	w := s.do(http.MethodPost, "/pullRequest/create", "key:bot-key", gin.H{
+		"pull_request_id": "pr-1", "pull_request_name": "Add search", "author_id": "u1",
+	})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = s.do(http.MethodPost, "/pullRequest/merge", "key:bot-key", gin.H{"pull_request_id": "pr-1"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, "FORBIDDEN", errorCode(t, w))
+
+	// Участник не создаёт PR от чужого имени
+	w = s.do(http.MethodPost, "/pullRequest/create", s.token("u2", auth.RoleMember), gin.H{
+		"pull_request_id": "pr-2", "pull_request_name": "Fix", "author_id": "u1",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestTeamScopedActions(t *testing.T) {
+	s := newAuthTestServer(t)
+	settings := func(team string) gin.H {
+		return gin.H{"team_name": team, "min_reviewers": 1, "max_reviewers": 2}
+	}
+
+	w := s.do(http.MethodPost, "/team/settings", s.token("lead", auth.RoleTeamLead), settings("backend"))
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = s.do(http.MethodPost, "/team/settings", s.token("lead", auth.RoleTeamLead), settings("frontend"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/team/settings", s.token("u1", auth.RoleMember), settings("backend"))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/users/setIsActive", s.token("lead", auth.RoleTeamLead), gin.H{"user_id": "f1", "is_active": false})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/users/setIsActive", s.token("admin", auth.RoleAdmin), gin.H{"user_id": "f1", "is_active": false})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestReassignAndGetTeam(t *testing.T) {
+	s := newAuthTestServer(t)
+
+	pr, err := s.app.Service.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	reviewer := pr.AssignedReviewers[0]
+
+	// Ревьюер сам отдаёт ревью; в ответе — PR после переназначения
+	w := s.do(http.MethodPost, "/pullRequest/reassign", s.token(reviewer, auth.RoleMember), gin.H{
+		"pull_request_id": "pr-1", "old_user_id": reviewer,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var resp struct {
+		PR         models.PullRequest `json:"pr"`
+		ReplacedBy string             `json:"replaced_by"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.NotContains(t, resp.PR.AssignedReviewers, reviewer)
+	assert.Contains(t, resp.PR.AssignedReviewers, resp.ReplacedBy)
+
+	w = s.do(http.MethodGet, "/team/get?team_name=frontend", s.token("u1", auth.RoleMember), nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var team models.Team
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &team))
+	assert.Len(t, team.Members, 3)
+
+	w = s.do(http.MethodGet, "/team/get?team_name=unknown", s.token("u1", auth.RoleMember), nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTeamSyncByLeadKeepsForeignMembers(t *testing.T) {
+	s := newAuthTestServer(t)
+	roster := gin.H{
+		"team_name": "backend",
+		"members": []gin.H{
+			{"user_id": "lead", "username": "lead", "is_active": true},
+			{"user_id": "u1", "username": "u1", "is_active": true},
+			{"user_id": "f1", "username": "Renamed", "is_active": true},
+		},
+	}
+
+	// Лид не забирает и не переименовывает участника чужой команды
+	w := s.do(http.MethodPost, "/team/sync", s.token("lead", auth.RoleTeamLead), roster)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var result models.TeamSyncResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, []string{"f1"}, result.Conflicts)
+	assert.Empty(t, result.Moved)
+
+	user, err := s.app.Service.GetUser("f1")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", user.TeamName)
+	assert.Equal(t, "f1", user.Username)
+
+	// Администратор переводит его, как в /users/moveTeam
+	w = s.do(http.MethodPost, "/team/sync", s.token("admin", auth.RoleAdmin), roster)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Empty(t, result.Conflicts)
+	assert.Equal(t, []string{"f1"}, result.Moved)
+}
+
+func TestPRActionsByOwnership(t *testing.T) {
+	s := newAuthTestServer(t)
+
+	pr, err := s.app.Service.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	require.NotEmpty(t, pr.AssignedReviewers)
+	reviewer := pr.AssignedReviewers[0]
+
+	// Отзыв оставляют только от своего имени
+	w := s.do(http.MethodPost, "/pullRequest/review", s.token("u1", auth.RoleMember), gin.H{
+		"pull_request_id": "pr-1", "user_id": reviewer, "verdict": "APPROVED",
+	})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// Мержит только автор; лиду и другим участникам нельзя
+	w = s.do(http.MethodPost, "/pullRequest/merge", s.token("lead", auth.RoleTeamLead), gin.H{"pull_request_id": "pr-1"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/pullRequest/merge", s.token("f1", auth.RoleMember), gin.H{"pull_request_id": "pr-1"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// merged_by из запроса не подменяет автора действия
+	w = s.do(http.MethodPost, "/pullRequest/merge", s.token("u1", auth.RoleMember), gin.H{"pull_request_id": "pr-1", "merged_by": "lead"})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	events, err := s.app.Service.GetPRHistory("pr-1")
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, models.EventPRMerged, last.EventType)
+	assert.Equal(t, "u1", last.ActorID)
+}
+
+func TestCloseAllowsTeamLead(t *testing.T) {
+	s := newAuthTestServer(t)
+
+	_, err := s.app.Service.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	w := s.do(http.MethodPost, "/pullRequest/close", s.token("u2", auth.RoleMember), gin.H{"pull_request_id": "pr-1"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/pullRequest/close", s.token("lead", auth.RoleTeamLead), gin.H{"pull_request_id": "pr-1"})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestIdentityOwnership(t *testing.T) {
+	s := newAuthTestServer(t)
+	u1 := s.token("u1", auth.RoleMember)
+
+	// Учётную запись хостинга сам пользователь не привязывает: по ней вебхуки определяют автора PR
+	identity := map[string]string{"user_id": "u1", "provider": "github", "external_id": "octocat"}
+	w := s.do(http.MethodPost, "/users/addIdentity", u1, identity)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = s.do(http.MethodPost, "/users/addIdentity", u1, map[string]string{"user_id": "u1", "provider": " GitLab ", "external_id": "u1"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	w = s.do(http.MethodPost, "/users/addIdentity", u1, map[string]string{"user_id": "u1", "provider": "github-id", "external_id": "583231"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/users/addIdentity", s.token("f1", auth.RoleTeamLead), identity)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/users/addIdentity", s.token("lead", auth.RoleTeamLead), identity)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	// Прочие учётные записи пользователь привязывает сам, но только себе
+	w = s.do(http.MethodPost, "/users/addIdentity", u1, map[string]string{"user_id": "u1", "provider": "slack", "external_id": "U123"})
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	w = s.do(http.MethodPost, "/users/addIdentity", u1, map[string]string{"user_id": "u2", "provider": "slack", "external_id": "U456"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/users/addIdentity", s.token("lead", auth.RoleTeamLead), map[string]string{"user_id": "u2", "provider": "github", "external_id": "octocat"})
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "IDENTITY_TAKEN", errorCode(t, w))
+
+	// Интеграции находят пользователя по внешней учётной записи
+	w = s.do(http.MethodGet, "/users/resolve?provider=github&external_id=OctoCat", "key:bot-key", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var resolved struct {
+		User models.User `json:"user"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resolved))
+	assert.Equal(t, "u1", resolved.User.UserID)
+
+	w = s.do(http.MethodPost, "/users/removeIdentity", s.token("f1", auth.RoleMember), identity)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/users/removeIdentity", u1, identity)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = s.do(http.MethodGet, "/users/resolve?provider=github&external_id=octocat", "key:bot-key", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
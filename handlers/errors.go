@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewtask/apperr"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errorStatuses — HTTP-статус для каждого кода ошибки API
+var errorStatuses = map[apperr.Code]int{
+	apperr.CodeBadRequest:         http.StatusBadRequest,
+	apperr.CodeNotFound:           http.StatusNotFound,
+	apperr.CodeTeamExists:         http.StatusBadRequest,
+	apperr.CodePRExists:           http.StatusConflict,
+	apperr.CodePRMerged:           http.StatusConflict,
+	apperr.CodeNotAssigned:        http.StatusConflict,
+	apperr.CodeNoCandidate:        http.StatusConflict,
+	apperr.CodeInvalidTransition:  http.StatusConflict,
+	apperr.CodeMergeBlocked:       http.StatusConflict,
+	apperr.CodeNotEnoughReviewers: http.StatusConflict,
+	apperr.CodeUserInTeam:         http.StatusConflict,
+	apperr.CodeIdentityTaken:      http.StatusConflict,
+	apperr.CodeUnauthorized:       http.StatusUnauthorized,
+	apperr.CodeForbidden:          http.StatusForbidden,
+	apperr.CodeInternal:           http.StatusInternalServerError,
+}
+
+// ErrorHandler отвечает на ошибку, переданную обработчиком через c.Error,
+// в формате {"error": {"code", "message", ...}}. Ошибки без кода
+// возвращаются как INTERNAL_ERROR.
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		status, body := errorResponse(c.Errors.Last().Err)
+		c.JSON(status, gin.H{
+			"error": body,
+		})
+	}
+}
+
+func errorResponse(err error) (int, map[string]interface{}) {
+	appErr, ok := apperr.From(err)
+	if !ok {
+		return http.StatusInternalServerError, map[string]interface{}{
+			"code":    apperr.CodeInternal,
+			"message": err.Error(),
+		}
+	}
+
+	status, ok := errorStatuses[appErr.Code]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	body := map[string]interface{}{
+		"code":    appErr.Code,
+		"message": appErr.Message,
+	}
+	for key, value := range appErr.Details {
+		body[key] = value
+	}
+
+	return status, body
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"reviewtask/apperr"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errorBody struct {
+	Error map[string]interface{} `json:"error"`
+}
+
+// serveError возвращает ответ ErrorHandler на ошибку err
+func serveError(t *testing.T, err error) (int, map[string]interface{}) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(ErrorHandler())
+	r.GET("/", func(c *gin.Context) {
+		c.Error(err)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var body errorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	return w.Code, body.Error
+}
+
+func TestErrorHandlerCodes(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{apperr.ErrInvalidBody, http.StatusBadRequest},
+		{apperr.ErrTeamNotFound, http.StatusNotFound},
+		{apperr.ErrUserNotFound, http.StatusNotFound},
+		{apperr.ErrPRNotFound, http.StatusNotFound},
+		{apperr.ErrTeamExists, http.StatusBadRequest},
+		{apperr.ErrPRExists, http.StatusConflict},
+		{apperr.ErrPRMerged, http.StatusConflict},
+		{apperr.ErrNotAssigned, http.StatusConflict},
+		{apperr.ErrNoCandidate, http.StatusConflict},
+		{apperr.ErrInvalidTransition, http.StatusConflict},
+		{apperr.ErrMergeBlocked, http.StatusConflict},
+		{apperr.ErrNotEnoughReviewers, http.StatusConflict},
+		{apperr.New(apperr.CodeUserInTeam, "user already in team"), http.StatusConflict},
+		{apperr.ErrIdentityTaken, http.StatusConflict},
+		{apperr.ErrUnauthorized, http.StatusUnauthorized},
+		{apperr.ErrForbidden, http.StatusForbidden},
+		{apperr.New(apperr.CodeInternal, "boom"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		appErr, _ := apperr.From(tt.err)
+		t.Run(string(appErr.Code), func(t *testing.T) {
+			status, body := serveError(t, tt.err)
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, string(appErr.Code), body["code"])
+			assert.Equal(t, appErr.Message, body["message"])
+		})
+	}
+}
+
+// Каждый код из таблицы ошибок README должен отображаться в HTTP-статус
+func TestErrorHandlerCoversReadmeCodes(t *testing.T) {
+	readme, err := os.ReadFile("../README.md")
+	require.NoError(t, err)
+
+	section := regexp.MustCompile("(?s)## 🛑 Ошибки(.*?)\n---").FindSubmatch(readme)
+	require.NotNil(t, section, "README has no errors section")
+
+	codes := regexp.MustCompile("(?m)^\\| `([A-Z_]+)` \\|").FindAllSubmatch(section[1], -1)
+	require.NotEmpty(t, codes)
+
+	for _, match := range codes {
+		code := apperr.Code(match[1])
+		t.Run(string(code), func(t *testing.T) {
+			want, ok := errorStatuses[code]
+			require.True(t, ok, "no HTTP status for %s", code)
+
+			status, body := serveError(t, apperr.New(code, "message"))
+			assert.Equal(t, want, status)
+			assert.Equal(t, string(code), body["code"])
+			if code != apperr.CodeInternal {
+				assert.NotEqual(t, http.StatusInternalServerError, status)
+			}
+		})
+	}
+}
+
+func TestErrorHandlerWrappedError(t *testing.T) {
+	status, body := serveError(t, fmt.Errorf("reassign: %w", apperr.ErrPRNotFound))
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "NOT_FOUND", body["code"])
+	assert.Equal(t, "PR not found", body["message"])
+}
+
+func TestErrorHandlerUnknownError(t *testing.T) {
+	status, body := serveError(t, errors.New("connection refused"))
+	assert.Equal(t, http.StatusInternalServerError, status)
+	assert.Equal(t, "INTERNAL_ERROR", body["code"])
+	assert.Equal(t, "connection refused", body["message"])
+}
+
+func TestErrorHandlerDetails(t *testing.T) {
+	err := apperr.ErrMergeBlocked.WithDetails("failed_rules", []string{"HAS_REVIEWER"})
+	status, body := serveError(t, err)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Equal(t, "MERGE_BLOCKED", body["code"])
+	assert.Equal(t, []interface{}{"HAS_REVIEWER"}, body["failed_rules"])
+}
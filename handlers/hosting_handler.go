@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"reviewtask/apperr"
+	"reviewtask/hosting"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxHostingPayload — предельный размер вебхука хостинга
+const maxHostingPayload = 5 << 20
+
+// HostingWebhookHandler принимает вебхуки PR от GitHub или GitLab. Запрос
+// аутентифицируется подписью хостинга, а не токеном сервиса.
+func HostingWebhookHandler(receiver *hosting.Receiver, provider hosting.Provider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxHostingPayload+1))
+		if err != nil || len(body) > maxHostingPayload {
+			c.Error(apperr.ErrInvalidBody)
+			return
+		}
+
+		event, err := receiver.Parse(provider, c.Request.Header, body)
+		if errors.Is(err, hosting.ErrUnsupportedEvent) {
+			c.JSON(http.StatusOK, gin.H{
+				"result": hosting.ResultIgnored,
+			})
+			return
+		}
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		result, err := receiver.Handle(event)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
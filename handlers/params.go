@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseOptionalTime читает необязательный параметр времени (RFC3339 или YYYY-MM-DD)
+// и приводит его к UTC: в БД время хранится без часового пояса, и смещение
+// клиента иначе потерялось бы при сравнении
+func parseOptionalTime(c *gin.Context, param string) (*time.Time, error) {
+	value := c.Query(param)
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := parseTimeParam(value)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, param+" must be RFC3339 or YYYY-MM-DD")
+	}
+	t = t.UTC()
+	return &t, nil
+}
+
+// parseTimeWindow читает окно [fromParam, toParam) и проверяет, что начало раньше конца;
+// ошибка называет параметры запроса
+func parseTimeWindow(c *gin.Context, fromParam, toParam string) (*time.Time, *time.Time, error) {
+	from, err := parseOptionalTime(c, fromParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := parseOptionalTime(c, toParam)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, nil, apperr.New(apperr.CodeBadRequest, fromParam+" must be before "+toParam)
+	}
+	return from, to, nil
+}
+
+// parseOptionalInt читает необязательный целочисленный параметр; 0 — параметр не задан
+func parseOptionalInt(c *gin.Context, param string) (int, error) {
+	value := c.Query(param)
+	if value == "" {
+		return 0, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, apperr.New(apperr.CodeBadRequest, param+" must be an integer")
+	}
+	return n, nil
+}
+
+// parseCursor читает курсор страницы из параметра cursor
+func parseCursor(c *gin.Context) (*models.PageCursor, error) {
+	value := c.Query("cursor")
+	if value == "" {
+		return nil, nil
+	}
+
+	cursor, err := models.DecodePageCursor(value)
+	if err != nil {
+		return nil, apperr.New(apperr.CodeBadRequest, "invalid cursor")
+	}
+	return cursor, nil
+}
+
+func parseTimeParam(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -3,6 +3,9 @@ package handlers
 import (
 	"log"
 	"net/http"
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,46 +15,25 @@ func (app *App) CreatePRHandler(c *gin.Context) {
 		PullRequestID   string `json:"pull_request_id"`
 		PullRequestName string `json:"pull_request_name"`
 		AuthorID        string `json:"author_id"`
+		Draft           bool   `json:"draft"`
 	}
 
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": map[string]interface{}{
-				"code":    "BAD_REQUEST",
-				"message": "invalid request body",
-			},
-		})
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := authorizePRCreate(c, req.AuthorID); err != nil {
+		c.Error(err)
 		return
 	}
 
 	log.Printf("Creating PR: id=%s, name=%s, author_id=%s",
 		req.PullRequestID, req.PullRequestName, req.AuthorID)
 
-	pr, err := app.Service.CreatePRWithReviewers(req.PullRequestID, req.PullRequestName, req.AuthorID)
+	pr, err := app.Service.CreatePRWithReviewers(req.PullRequestID, req.PullRequestName, req.AuthorID, req.Draft)
 	if err != nil {
-		switch err.Error() {
-		case "PR id already exists":
-			c.JSON(http.StatusConflict, gin.H{
-				"error": map[string]interface{}{
-					"code":    "PR_EXISTS",
-					"message": "PR id already exists",
-				},
-			})
-		case "author not found":
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": map[string]interface{}{
-					"code":    "NOT_FOUND",
-					"message": "author/team not found",
-				},
-			})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": map[string]interface{}{
-					"code":    "INTERNAL_ERROR",
-					"message": err.Error(),
-				},
-			})
-		}
+		c.Error(err)
 		return
 	}
 
@@ -64,26 +46,22 @@ func (app *App) CreatePRHandler(c *gin.Context) {
 func (app *App) MergePRHandler(c *gin.Context) {
 	var req struct {
 		PullRequestID string `json:"pull_request_id"`
+		MergedBy      string `json:"merged_by"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
 	}
 
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": map[string]interface{}{
-				"code":    "BAD_REQUEST",
-				"message": "invalid request body",
-			},
-		})
+	if err := app.authorizePRAuthor(c, req.PullRequestID, false); err != nil {
+		c.Error(err)
 		return
 	}
 
-	pr, err := app.Service.MergePR(req.PullRequestID)
+	pr, err := app.Service.MergePR(req.PullRequestID, actorID(c, req.MergedBy))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": map[string]interface{}{
-				"code":    "NOT_FOUND",
-				"message": "PR not found",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
@@ -96,69 +74,30 @@ func (app *App) ReassignReviewerHandler(c *gin.Context) {
 	var req struct {
 		PullRequestID string `json:"pull_request_id"`
 		OldUserID     string `json:"old_user_id"`
+		ActorID       string `json:"actor_id"`
+		Reason        string `json:"reason"`
 	}
 
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": map[string]interface{}{
-				"code":    "BAD_REQUEST",
-				"message": "invalid request body",
-			},
-		})
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
 		return
 	}
 
-	newReviewer, err := app.Service.ReassignReviewer(req.PullRequestID, req.OldUserID)
+	if err := app.authorizeReassign(c, req.PullRequestID, req.OldUserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	newReviewer, err := app.Service.ReassignReviewer(req.PullRequestID, req.OldUserID, actorID(c, req.ActorID), req.Reason)
 	if err != nil {
-		switch err.Error() {
-		case "cannot reassign on merged PR":
-			c.JSON(http.StatusConflict, gin.H{
-				"error": map[string]interface{}{
-					"code":    "PR_MERGED",
-					"message": "cannot reassign on merged PR",
-				},
-			})
-		case "reviewer is not assigned to this PR":
-			c.JSON(http.StatusConflict, gin.H{
-				"error": map[string]interface{}{
-					"code":    "NOT_ASSIGNED",
-					"message": "reviewer is not assigned to this PR",
-				},
-			})
-		case "no active replacement candidate in team":
-			c.JSON(http.StatusConflict, gin.H{
-				"error": map[string]interface{}{
-					"code":    "NO_CANDIDATE",
-					"message": "no active replacement candidate in team",
-				},
-			})
-		case "PR not found", "old reviewer not found":
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": map[string]interface{}{
-					"code":    "NOT_FOUND",
-					"message": err.Error(),
-				},
-			})
-		default:
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": map[string]interface{}{
-					"code":    "INTERNAL_ERROR",
-					"message": err.Error(),
-				},
-			})
-		}
+		c.Error(err)
 		return
 	}
 
 	// Получаем обновленный PR
-	pr, err := app.Repo.GetPR(req.PullRequestID)
+	pr, err := app.Service.GetPR(req.PullRequestID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": map[string]interface{}{
-				"code":    "INTERNAL_ERROR",
-				"message": "failed to get updated PR",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
@@ -167,3 +106,169 @@ func (app *App) ReassignReviewerHandler(c *gin.Context) {
 		"replaced_by": newReviewer,
 	})
 }
+
+func (app *App) ClosePRHandler(c *gin.Context) {
+	app.transitionPRHandler(c, app.Service.ClosePR)
+}
+
+func (app *App) ReopenPRHandler(c *gin.Context) {
+	app.transitionPRHandler(c, app.Service.ReopenPR)
+}
+
+func (app *App) MarkReadyHandler(c *gin.Context) {
+	app.transitionPRHandler(c, app.Service.MarkReady)
+}
+
+// transitionPRHandler — общий обработчик смены статуса PR
+func (app *App) transitionPRHandler(c *gin.Context, transition func(prID, actorID, reason string) (*models.PullRequest, error)) {
+	var req struct {
+		PullRequestID string `json:"pull_request_id"`
+		ActorID       string `json:"actor_id"`
+		Reason        string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizePRAuthor(c, req.PullRequestID, true); err != nil {
+		c.Error(err)
+		return
+	}
+
+	pr, err := transition(req.PullRequestID, actorID(c, req.ActorID), req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": pr,
+	})
+}
+
+func (app *App) SubmitReviewHandler(c *gin.Context) {
+	var req struct {
+		PullRequestID string               `json:"pull_request_id"`
+		UserID        string               `json:"user_id"`
+		Verdict       models.ReviewVerdict `json:"verdict"`
+		Comment       string               `json:"comment"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := authorizeSelf(c, req.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	pr, err := app.Service.SubmitReview(req.PullRequestID, req.UserID, req.Verdict, req.Comment)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": pr,
+	})
+}
+
+func (app *App) GetPRHistoryHandler(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		c.Error(apperr.New(apperr.CodeBadRequest, "pull_request_id parameter is required"))
+		return
+	}
+
+	events, err := app.Service.GetPRHistory(prID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pull_request_id": prID,
+		"events":          events,
+	})
+}
+
+func (app *App) GetPRHandler(c *gin.Context) {
+	prID := c.Query("pull_request_id")
+	if prID == "" {
+		c.Error(apperr.New(apperr.CodeBadRequest, "pull_request_id parameter is required"))
+		return
+	}
+
+	pr, err := app.Service.GetPR(prID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pr": pr,
+	})
+}
+
+// ListPRsHandler — поиск PR. Кроме общих параметров списка принимает reviewer_id,
+// name (подстрока названия), created_from/created_to и merged_from/merged_to.
+func (app *App) ListPRsHandler(c *gin.Context) {
+	filter, err := parsePRFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter.ReviewerID = c.Query("reviewer_id")
+	filter.NameContains = c.Query("name")
+
+	if filter.CreatedFrom, filter.CreatedTo, err = parseTimeWindow(c, "created_from", "created_to"); err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.MergedFrom, filter.MergedTo, err = parseTimeWindow(c, "merged_from", "merged_to"); err != nil {
+		c.Error(err)
+		return
+	}
+
+	page, err := app.Service.ListPRs(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parsePRFilter читает общие параметры списков PR: status (через запятую),
+// author_id, team_name, sort, order, limit и cursor
+func parsePRFilter(c *gin.Context) (models.PRFilter, error) {
+	filter := models.PRFilter{
+		AuthorID: c.Query("author_id"),
+		TeamName: c.Query("team_name"),
+		SortBy:   models.PRSortField(c.Query("sort")),
+		Order:    models.SortOrder(c.Query("order")),
+	}
+
+	for _, value := range c.QueryArray("status") {
+		for _, status := range strings.Split(value, ",") {
+			if status != "" {
+				filter.Statuses = append(filter.Statuses, models.PRStatus(strings.ToUpper(status)))
+			}
+		}
+	}
+
+	var err error
+	if filter.Limit, err = parseOptionalInt(c, "limit"); err != nil {
+		return filter, err
+	}
+	if filter.Cursor, err = parseCursor(c); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
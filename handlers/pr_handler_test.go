@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"reviewtask/auth"
+	"reviewtask/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListPRsValidatesTimeParamsInOrder(t *testing.T) {
+	s := newAuthTestServer(t)
+	member := s.token("u1", auth.RoleMember)
+
+	// При нескольких ошибках ответ всегда называет первый по порядку параметр
+	for i := 0; i < 20; i++ {
+		w := s.do(http.MethodGet, "/pullRequest/list?merged_to=x&merged_from=x&created_to=x&created_from=x", member, nil)
+		require.Equal(t, http.StatusBadRequest, w.Code)
+
+		var body errorBody
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, "created_from must be RFC3339 or YYYY-MM-DD", body.Error["message"])
+	}
+
+	w := s.do(http.MethodGet, "/pullRequest/list?merged_from=2024-02-01&merged_to=2024-01-01", member, nil)
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	var body errorBody
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "merged_from must be before merged_to", body.Error["message"])
+
+	w = s.do(http.MethodGet, "/pullRequest/list?created_from=2024-01-01&created_to=2024-01-02", member, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page models.PRPage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+}
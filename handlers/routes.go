@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"reviewtask/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterRoutes регистрирует эндпоинты API в группе с аутентификацией.
+// Права на конкретную команду или PR проверяют сами обработчики.
+func (app *App) RegisterRoutes(api *gin.RouterGroup) {
+	admin := RequireRoles(auth.RoleAdmin)
+	// Изменения от имени людей
+	people := RequireRoles(auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember)
+	// Интеграции: боты создают PR и синхронизируют составы команд
+	integrations := RequireRoles(auth.RoleAdmin, auth.RoleTeamLead, auth.RoleMember, auth.RoleBot)
+
+	// Teams endpoints
+	api.POST("/team/add", admin, app.CreateTeamHandler)
+	api.GET("/team/get", app.GetTeamHandler)
+	api.POST("/team/setStrategy", people, app.SetTeamStrategyHandler)
+	api.POST("/team/setPartners", people, app.SetTeamPartnersHandler)
+	api.POST("/team/addMember", people, app.AddTeamMemberHandler)
+	api.POST("/team/removeMember", people, app.RemoveTeamMemberHandler)
+	api.POST("/team/sync", integrations, app.SyncTeamHandler)
+	api.GET("/team/settings", app.GetTeamSettingsHandler)
+	api.POST("/team/settings", people, app.UpdateTeamSettingsHandler)
+
+	// Users endpoints
+	api.POST("/users/setIsActive", people, app.SetUserActiveHandler)
+	api.GET("/users/getReview", app.GetUserReviewHandler)
+	api.POST("/users/deactivate", people, app.DeactivateUsersHandler)
+	api.POST("/users/moveTeam", admin, app.MoveUserTeamHandler)
+	api.POST("/users/addAbsence", people, app.AddAbsenceHandler)
+	api.GET("/users/absences", app.GetAbsencesHandler)
+	api.POST("/users/cancelAbsence", people, app.CancelAbsenceHandler)
+	api.POST("/users/addIdentity", people, app.AddIdentityHandler)
+	api.GET("/users/identities", app.GetIdentitiesHandler)
+	api.POST("/users/removeIdentity", people, app.RemoveIdentityHandler)
+	api.GET("/users/resolve", app.ResolveUserHandler)
+
+	// Pull Request endpoints
+	api.POST("/pullRequest/create", integrations, app.CreatePRHandler)
+	api.POST("/pullRequest/merge", people, app.MergePRHandler)
+	api.POST("/pullRequest/reassign", people, app.ReassignReviewerHandler)
+	api.POST("/pullRequest/close", people, app.ClosePRHandler)
+	api.POST("/pullRequest/reopen", people, app.ReopenPRHandler)
+	api.POST("/pullRequest/markReady", people, app.MarkReadyHandler)
+	api.POST("/pullRequest/review", people, app.SubmitReviewHandler)
+	api.GET("/pullRequest/history", app.GetPRHistoryHandler)
+	api.GET("/pullRequest/get", app.GetPRHandler)
+	api.GET("/pullRequest/list", app.ListPRsHandler)
+
+	// Webhooks endpoints
+	api.POST("/webhooks/subscribe", people, app.CreateWebhookHandler)
+	api.GET("/webhooks/list", people, app.ListWebhooksHandler)
+	api.POST("/webhooks/delete", people, app.DeleteWebhookHandler)
+	api.GET("/webhooks/deadLetters", people, app.WebhookDeadLettersHandler)
+	api.POST("/webhooks/redeliver", admin, app.RedeliverWebhookHandler)
+
+	// Statistics endpoints
+	api.GET("/stats/users", app.UserStatsHandler)
+	api.GET("/stats/teams", app.TeamStatsHandler)
+	api.GET("/stats/mergeTime", app.MergeTimeStatsHandler)
+	api.GET("/stats/reassignments", app.ReassignmentStatsHandler)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewtask/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func (app *App) UserStatsHandler(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stats, err := app.Service.GetUserStats(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": stats,
+	})
+}
+
+func (app *App) TeamStatsHandler(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	stats, err := app.Service.GetTeamStats(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"teams": stats,
+	})
+}
+
+func (app *App) MergeTimeStatsHandler(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := app.Service.GetMergeTimeStats(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (app *App) ReassignmentStatsHandler(c *gin.Context) {
+	filter, err := parseStatsFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := app.Service.GetReassignmentStats(filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseStatsFilter читает параметры from, to (RFC3339 или YYYY-MM-DD) и team_name
+func parseStatsFilter(c *gin.Context) (models.StatsFilter, error) {
+	filter := models.StatsFilter{TeamName: c.Query("team_name")}
+
+	var err error
+	if filter.From, err = parseOptionalTime(c, "from"); err != nil {
+		return filter, err
+	}
+	if filter.To, err = parseOptionalTime(c, "to"); err != nil {
+		return filter, err
+	}
+
+	return filter, nil
+}
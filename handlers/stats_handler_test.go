@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatsFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parse := func(query string) (models.StatsFilter, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/stats/users?"+query, nil)
+		return parseStatsFilter(c)
+	}
+
+	filter, err := parse("from=2025-01-01&to=2025-02-01T12:00:00%2B03:00&team_name=backend")
+	require.NoError(t, err)
+	require.NotNil(t, filter.From)
+	require.NotNil(t, filter.To)
+	assert.True(t, filter.From.Equal(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, filter.To.Equal(time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "backend", filter.TeamName)
+
+	filter, err = parse("")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatsFilter{}, filter)
+
+	for _, query := range []string{"from=yesterday", "to=2025-13-01", "from=01.02.2025"} {
+		_, err := parse(query)
+		assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""), query)
+	}
+}
@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"reviewtask/apperr"
+	"reviewtask/auth"
 	"reviewtask/models"
 
 	"github.com/gin-gonic/gin"
@@ -9,32 +11,13 @@ import (
 
 func (app *App) CreateTeamHandler(c *gin.Context) {
 	var team models.Team
-	if err := c.BindJSON(&team); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": map[string]interface{}{
-				"code":    "BAD_REQUEST",
-				"message": "invalid request body",
-			},
-		})
+	if err := c.ShouldBindJSON(&team); err != nil {
+		c.Error(apperr.ErrInvalidBody)
 		return
 	}
 
 	if err := app.Service.CreateTeam(&team); err != nil {
-		if err.Error() == "team_name already exists" {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": map[string]interface{}{
-					"code":    "TEAM_EXISTS",
-					"message": "team_name already exists",
-				},
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": map[string]interface{}{
-				"code":    "INTERNAL_ERROR",
-				"message": err.Error(),
-			},
-		})
+		c.Error(err)
 		return
 	}
 
@@ -46,25 +29,209 @@ func (app *App) CreateTeamHandler(c *gin.Context) {
 func (app *App) GetTeamHandler(c *gin.Context) {
 	teamName := c.Query("team_name")
 	if teamName == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": map[string]interface{}{
-				"code":    "BAD_REQUEST",
-				"message": "team_name parameter is required",
-			},
-		})
+		c.Error(apperr.New(apperr.CodeBadRequest, "team_name parameter is required"))
 		return
 	}
 
-	team, err := app.Repo.GetTeam(teamName)
+	team, err := app.Service.GetTeam(teamName)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": map[string]interface{}{
-				"code":    "NOT_FOUND",
-				"message": "team not found",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, team)
 }
+
+func (app *App) SetTeamStrategyHandler(c *gin.Context) {
+	var req struct {
+		TeamName         string                  `json:"team_name"`
+		ReviewerStrategy models.ReviewerStrategy `json:"reviewer_strategy"`
+		StrategyParams   models.StrategyParams   `json:"strategy_params"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizeTeam(c, req.TeamName); err != nil {
+		c.Error(err)
+		return
+	}
+
+	team, err := app.Service.SetTeamStrategy(req.TeamName, req.ReviewerStrategy, req.StrategyParams)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team": team,
+	})
+}
+
+func (app *App) GetTeamSettingsHandler(c *gin.Context) {
+	teamName := c.Query("team_name")
+	if teamName == "" {
+		c.Error(apperr.New(apperr.CodeBadRequest, "team_name parameter is required"))
+		return
+	}
+
+	settings, err := app.Service.GetTeamSettings(teamName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings": settings,
+	})
+}
+
+func (app *App) UpdateTeamSettingsHandler(c *gin.Context) {
+	var patch models.TeamSettingsPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizeTeam(c, patch.TeamName); err != nil {
+		c.Error(err)
+		return
+	}
+
+	updated, err := app.Service.UpdateTeamSettings(&patch)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"settings": updated,
+	})
+}
+
+func (app *App) SetTeamPartnersHandler(c *gin.Context) {
+	var req struct {
+		TeamName     string   `json:"team_name"`
+		PartnerTeams []string `json:"partner_teams"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizeTeam(c, req.TeamName); err != nil {
+		c.Error(err)
+		return
+	}
+
+	team, err := app.Service.SetTeamPartners(req.TeamName, req.PartnerTeams)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"team": team,
+	})
+}
+
+func (app *App) AddTeamMemberHandler(c *gin.Context) {
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+		Username string `json:"username"`
+		IsActive *bool  `json:"is_active"`
+		ActorID  string `json:"actor_id"`
+		Reason   string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizeTeam(c, req.TeamName); err != nil {
+		c.Error(err)
+		return
+	}
+
+	member := models.TeamMember{UserID: req.UserID, Username: req.Username, IsActive: true}
+	if req.IsActive != nil {
+		member.IsActive = *req.IsActive
+	}
+
+	change, err := app.Service.AddTeamMember(req.TeamName, member, actorID(c, req.ActorID), req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+func (app *App) RemoveTeamMemberHandler(c *gin.Context) {
+	var req struct {
+		TeamName string `json:"team_name"`
+		UserID   string `json:"user_id"`
+		ActorID  string `json:"actor_id"`
+		Reason   string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizeTeam(c, req.TeamName); err != nil {
+		c.Error(err)
+		return
+	}
+
+	change, err := app.Service.RemoveTeamMember(req.TeamName, req.UserID, actorID(c, req.ActorID), req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
+
+func (app *App) SyncTeamHandler(c *gin.Context) {
+	var req struct {
+		TeamName          string              `json:"team_name"`
+		Members           []models.TeamMember `json:"members"`
+		DeactivateMissing bool                `json:"deactivate_missing"`
+		ActorID           string              `json:"actor_id"`
+		Reason            string              `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	// Боты синхронизируют составы любых команд из внешних каталогов
+	principal := currentPrincipal(c)
+	if !principal.HasRole(auth.RoleBot) {
+		if err := app.authorizeTeam(c, req.TeamName); err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	// Лид управляет только своей командой: участников других команд
+	// переводят администратор (как в /users/moveTeam) и боты
+	moveMembers := principal.HasRole(auth.RoleAdmin) || principal.HasRole(auth.RoleBot)
+
+	team := &models.Team{TeamName: req.TeamName, Members: req.Members}
+	result, err := app.Service.SyncTeam(team, req.DeactivateMissing, moveMembers, actorID(c, req.ActorID), req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
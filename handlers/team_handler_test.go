@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewtask/apperr"
+	"reviewtask/auth"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Клиент, не знающий о правилах мерджа, не должен отключать их, меняя число ревьюеров
+func TestUpdateTeamSettingsKeepsMergeRules(t *testing.T) {
+	s := newAuthTestServer(t)
+	lead := s.token("lead", auth.RoleTeamLead)
+
+	w := s.do(http.MethodPost, "/team/settings", lead, gin.H{
+		"team_name": "backend", "required_approvals": 1, "block_on_changes_requested": true,
+		"require_reviewer": true, "disallow_self_merge": true,
+	})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = s.do(http.MethodPost, "/team/settings", lead, gin.H{"team_name": "backend", "min_reviewers": 1, "max_reviewers": 3})
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	settings, err := s.app.Service.GetTeamSettings("backend")
+	require.NoError(t, err)
+	assert.Equal(t, 3, settings.MaxReviewers)
+	assert.Equal(t, 1, settings.RequiredApprovals)
+	assert.True(t, settings.BlockOnChangesRequested)
+	assert.True(t, settings.RequireReviewer)
+	assert.True(t, settings.DisallowSelfMerge)
+
+	_, err = s.app.Service.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	_, err = s.app.Service.MergePR("pr-1", "u1")
+	assert.ErrorIs(t, err, apperr.ErrMergeBlocked)
+}
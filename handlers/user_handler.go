@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"net/http"
+	"reviewtask/apperr"
+	"reviewtask/models"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,26 +12,23 @@ func (app *App) SetUserActiveHandler(c *gin.Context) {
 	var req struct {
 		UserID   string `json:"user_id"`
 		IsActive bool   `json:"is_active"`
+		ActorID  string `json:"actor_id"`
+		Reason   string `json:"reason"`
 	}
 
-	if err := c.BindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": map[string]interface{}{
-				"code":    "BAD_REQUEST",
-				"message": "invalid request body",
-			},
-		})
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
 		return
 	}
 
-	user, err := app.Service.SetUserActive(req.UserID, req.IsActive)
+	if err := app.authorizeTeamOf(c, req.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	user, err := app.Service.SetUserActive(req.UserID, req.IsActive, actorID(c, req.ActorID), req.Reason)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": map[string]interface{}{
-				"code":    "NOT_FOUND",
-				"message": "user not found",
-			},
-		})
+		c.Error(err)
 		return
 	}
 
@@ -38,31 +37,257 @@ func (app *App) SetUserActiveHandler(c *gin.Context) {
 	})
 }
 
+// GetUserReviewHandler — страница PR, на которые назначен пользователь.
+// Кроме общих параметров списка PR принимает from и to — окно по времени создания.
 func (app *App) GetUserReviewHandler(c *gin.Context) {
 	userID := c.Query("user_id")
 	if userID == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": map[string]interface{}{
-				"code":    "BAD_REQUEST",
-				"message": "user_id parameter is required",
-			},
-		})
+		c.Error(apperr.New(apperr.CodeBadRequest, "user_id parameter is required"))
+		return
+	}
+
+	filter, err := parsePRFilter(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if filter.CreatedFrom, filter.CreatedTo, err = parseTimeWindow(c, "from", "to"); err != nil {
+		c.Error(err)
 		return
 	}
 
-	prs, err := app.Service.GetUserReviewPRs(userID)
+	page, err := app.Service.GetUserReviewPRs(userID, filter)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"error": map[string]interface{}{
-				"code":    "NOT_FOUND",
-				"message": err.Error(),
-			},
-		})
+		c.Error(err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
 		"user_id":       userID,
-		"pull_requests": prs,
+		"pull_requests": page.PullRequests,
+		"total":         page.Total,
+		"next_cursor":   page.NextCursor,
 	})
 }
+
+func (app *App) DeactivateUsersHandler(c *gin.Context) {
+	var req struct {
+		UserIDs  []string `json:"user_ids"`
+		TeamName string   `json:"team_name"`
+		ActorID  string   `json:"actor_id"`
+		Reason   string   `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizeDeactivation(c, req.UserIDs, req.TeamName); err != nil {
+		c.Error(err)
+		return
+	}
+
+	report, err := app.Service.DeactivateUsers(req.UserIDs, req.TeamName, actorID(c, req.ActorID), req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (app *App) AddAbsenceHandler(c *gin.Context) {
+	var absence models.Absence
+	if err := c.ShouldBindJSON(&absence); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizeUser(c, absence.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	created, err := app.Service.AddAbsence(&absence)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"absence": created,
+	})
+}
+
+func (app *App) GetAbsencesHandler(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.Error(apperr.New(apperr.CodeBadRequest, "user_id parameter is required"))
+		return
+	}
+
+	absences, err := app.Service.GetUserAbsences(userID, c.Query("include_past") == "true")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":  userID,
+		"absences": absences,
+	})
+}
+
+func (app *App) CancelAbsenceHandler(c *gin.Context) {
+	var req struct {
+		AbsenceID int64 `json:"absence_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	existing, err := app.Service.GetAbsence(req.AbsenceID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := app.authorizeUser(c, existing.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	absence, err := app.Service.CancelAbsence(req.AbsenceID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"absence": absence,
+	})
+}
+
+func (app *App) AddIdentityHandler(c *gin.Context) {
+	var identity models.UserIdentity
+	if err := c.ShouldBindJSON(&identity); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	// Учётная запись хостинга делает пользователя автором PR из вебхуков,
+	// поэтому её привязывает лид команды или администратор, а не сам пользователь
+	authorize := app.authorizeUser
+	if models.IsHostingIdentity(identity.Provider) {
+		authorize = app.authorizeTeamOf
+	}
+	if err := authorize(c, identity.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	added, err := app.Service.AddUserIdentity(&identity)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"identity": added,
+	})
+}
+
+func (app *App) GetIdentitiesHandler(c *gin.Context) {
+	userID := c.Query("user_id")
+	if userID == "" {
+		c.Error(apperr.New(apperr.CodeBadRequest, "user_id parameter is required"))
+		return
+	}
+
+	identities, err := app.Service.ListUserIdentities(userID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":    userID,
+		"identities": identities,
+	})
+}
+
+func (app *App) RemoveIdentityHandler(c *gin.Context) {
+	var req struct {
+		Provider   string `json:"provider"`
+		ExternalID string `json:"external_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	existing, err := app.Service.GetUserIdentity(req.Provider, req.ExternalID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := app.authorizeUser(c, existing.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	identity, err := app.Service.RemoveUserIdentity(req.Provider, req.ExternalID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"identity": identity,
+	})
+}
+
+// ResolveUserHandler находит пользователя по внешней учётной записи
+func (app *App) ResolveUserHandler(c *gin.Context) {
+	provider, externalID := c.Query("provider"), c.Query("external_id")
+	if provider == "" || externalID == "" {
+		c.Error(apperr.New(apperr.CodeBadRequest, "provider and external_id parameters are required"))
+		return
+	}
+
+	user, err := app.Service.ResolveUser(provider, externalID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
+
+func (app *App) MoveUserTeamHandler(c *gin.Context) {
+	var req struct {
+		UserID      string `json:"user_id"`
+		TeamName    string `json:"team_name"`
+		KeepReviews bool   `json:"keep_open_reviews"`
+		ActorID     string `json:"actor_id"`
+		Reason      string `json:"reason"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	change, err := app.Service.MoveUserToTeam(req.UserID, req.TeamName, req.KeepReviews, actorID(c, req.ActorID), req.Reason)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, change)
+}
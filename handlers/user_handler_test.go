@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reviewtask/apperr"
+	"reviewtask/auth"
+	"reviewtask/models"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbsenceEndpoints(t *testing.T) {
+	s := newAuthTestServer(t)
+	u2 := s.token("u2", auth.RoleMember)
+
+	// Смещение клиента сохраняется: в ответе тот же момент в UTC
+	startsAt := time.Now().Add(-time.Hour).In(time.FixedZone("MSK", 3*60*60)).Truncate(time.Second)
+	endsAt := startsAt.Add(2 * time.Hour)
+	absence := gin.H{
+		"user_id":  "u2",
+		"startsAt": startsAt.Format(time.RFC3339),
+		"endsAt":   endsAt.Format(time.RFC3339),
+		"reason":   "vacation",
+	}
+
+	w := s.do(http.MethodPost, "/users/addAbsence", s.token("u1", auth.RoleMember), absence)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/users/addAbsence", u2, absence)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created struct {
+		Absence models.Absence `json:"absence"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.True(t, created.Absence.StartsAt.Equal(startsAt))
+	assert.Equal(t, startsAt.UTC().Format(time.RFC3339), created.Absence.StartsAt.Format(time.RFC3339))
+
+	w = s.do(http.MethodPost, "/users/addAbsence", u2, gin.H{
+		"user_id": "u2", "startsAt": endsAt.Format(time.RFC3339), "endsAt": startsAt.Format(time.RFC3339),
+	})
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Пока u2 отсутствует, единственный доступный ревьюер — u3
+	pr, err := s.app.Service.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	assert.NotContains(t, pr.AssignedReviewers, "u2")
+
+	absences := func(query string) []models.Absence {
+		t.Helper()
+		w := s.do(http.MethodGet, "/users/absences?"+query, u2, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var body struct {
+			Absences []models.Absence `json:"absences"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Absences
+	}
+	listed := absences("user_id=u2")
+	require.Len(t, listed, 1)
+	assert.Equal(t, created.Absence.ID, listed[0].ID)
+
+	w = s.do(http.MethodGet, "/users/absences", u2, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	cancel := gin.H{"absence_id": created.Absence.ID}
+	w = s.do(http.MethodPost, "/users/cancelAbsence", s.token("f1", auth.RoleMember), cancel)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = s.do(http.MethodPost, "/users/cancelAbsence", s.token("lead", auth.RoleTeamLead), cancel)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	w = s.do(http.MethodPost, "/users/cancelAbsence", u2, gin.H{"absence_id": 999})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "NOT_FOUND", errorCode(t, w))
+
+	assert.Empty(t, absences("user_id=u2"))
+	listed = absences("user_id=u2&include_past=true")
+	require.Len(t, listed, 1)
+	assert.NotNil(t, listed[0].CancelledAt)
+}
+
+func TestGetReviewParams(t *testing.T) {
+	s := newAuthTestServer(t)
+	u2 := s.token("u2", auth.RoleMember)
+
+	message := func(w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var body errorBody
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		msg, _ := body.Error["message"].(string)
+		return msg
+	}
+
+	w := s.do(http.MethodGet, "/users/getReview?user_id=u2&from=2025-10-02&to=2025-10-01", u2, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "from must be before to", message(w))
+
+	w = s.do(http.MethodGet, "/users/getReview?user_id=u2&from=2025-10-01&to=2025-10-02", u2, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// Два PR по два ревьюера из трёх кандидатов: хотя бы один ревьюер у них общий
+	first, err := s.app.Service.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	second, err := s.app.Service.CreatePRWithReviewers("pr-2", "Fix search", "u1", false)
+	require.NoError(t, err)
+	var reviewer string
+	for _, id := range first.AssignedReviewers {
+		if slices.Contains(second.AssignedReviewers, id) {
+			reviewer = id
+		}
+	}
+	require.NotEmpty(t, reviewer)
+	listURL := "/users/getReview?user_id=" + reviewer + "&limit=1"
+
+	w = s.do(http.MethodGet, listURL, u2, nil)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page struct {
+		NextCursor string `json:"next_cursor"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.NotEmpty(t, page.NextCursor)
+
+	// Курсор не переносится на другой порядок сортировки
+	w = s.do(http.MethodGet, listURL+"&order=asc&cursor="+page.NextCursor, u2, nil)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "cursor was issued for a different sort or order", message(w))
+
+	w = s.do(http.MethodGet, listURL+"&cursor="+page.NextCursor, u2, nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
+
+func TestParseTimeWindow(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	parse := func(query string) (*time.Time, *time.Time, error) {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodGet, "/users/getReview?"+query, nil)
+		return parseTimeWindow(c, "from", "to")
+	}
+
+	// Смещение клиента переводится в UTC, момент времени сохраняется
+	from, to, err := parse("from=2024-01-01T00:00:00%2B03:00&to=2024-01-02")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2023, 12, 31, 21, 0, 0, 0, time.UTC), *from)
+	assert.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), *to)
+
+	_, _, err = parse("from=2024-01-02&to=2024-01-02T02:00:00%2B03:00")
+	assert.EqualError(t, err, "from must be before to")
+	_, _, err = parse("from=soon")
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""))
+}
@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"net/http"
+	"reviewtask/apperr"
+	"reviewtask/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Подписки команды настраивают её лид и администратор, глобальные — только администратор
+
+func (app *App) CreateWebhookHandler(c *gin.Context) {
+	var sub models.WebhookSubscription
+	if err := c.ShouldBindJSON(&sub); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	if err := app.authorizeTeam(c, sub.TeamName); err != nil {
+		c.Error(err)
+		return
+	}
+
+	created, err := app.Service.CreateWebhookSubscription(&sub)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"subscription": created,
+	})
+}
+
+func (app *App) ListWebhooksHandler(c *gin.Context) {
+	teamName := c.Query("team_name")
+	if err := app.authorizeTeam(c, teamName); err != nil {
+		c.Error(err)
+		return
+	}
+
+	subs, err := app.Service.ListWebhookSubscriptions(teamName)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": subs,
+	})
+}
+
+func (app *App) DeleteWebhookHandler(c *gin.Context) {
+	var req struct {
+		SubscriptionID int64 `json:"subscription_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	sub, err := app.authorizeWebhook(c, req.SubscriptionID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if err := app.Service.DeleteWebhookSubscription(req.SubscriptionID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscription": sub,
+	})
+}
+
+func (app *App) WebhookDeadLettersHandler(c *gin.Context) {
+	subscriptionID, err := parseOptionalInt(c, "subscription_id")
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if subscriptionID == 0 {
+		err = app.authorizeTeam(c, "")
+	} else {
+		_, err = app.authorizeWebhook(c, int64(subscriptionID))
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	letters, err := app.Service.ListWebhookDeadLetters(int64(subscriptionID))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dead_letters": letters,
+	})
+}
+
+func (app *App) RedeliverWebhookHandler(c *gin.Context) {
+	var req struct {
+		DeadLetterID int64 `json:"dead_letter_id"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(apperr.ErrInvalidBody)
+		return
+	}
+
+	delivery, err := app.Service.RedeliverWebhook(req.DeadLetterID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"delivery": delivery,
+	})
+}
+
+// authorizeWebhook возвращает подписку, если вызывающий может ей управлять
+func (app *App) authorizeWebhook(c *gin.Context, subscriptionID int64) (*models.WebhookSubscription, error) {
+	sub, err := app.Service.GetWebhookSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := app.authorizeTeam(c, sub.TeamName); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
@@ -0,0 +1,66 @@
+// Package hosting принимает вебхуки pull request'ов GitHub и GitLab
+// и отражает их в сервисе: создание, закрытие, мердж и переоткрытие PR.
+package hosting
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Provider — Git-хостинг, приславший вебхук
+type Provider string
+
+const (
+	ProviderGitHub Provider = "github"
+	ProviderGitLab Provider = "gitlab"
+)
+
+// Action — что произошло с PR на хостинге
+type Action string
+
+const (
+	ActionOpened         Action = "opened"
+	ActionReadyForReview Action = "ready_for_review"
+	ActionClosed         Action = "closed"
+	ActionMerged         Action = "merged"
+	ActionReopened       Action = "reopened"
+)
+
+// ErrUnsupportedEvent — вебхук корректен, но не требует действий (ping, правка описания и т.п.)
+var ErrUnsupportedEvent = errors.New("unsupported webhook event")
+
+// Account — пользователь хостинга: логин и числовой идентификатор
+type Account struct {
+	Login string
+	ID    string
+}
+
+// Event — событие PR, приведённое к общему для хостингов виду
+type Event struct {
+	Provider   Provider
+	Action     Action
+	Repository string
+	Number     int64
+	Title      string
+	Draft      bool
+	// Author — автор PR, Actor — кто выполнил действие
+	Author Account
+	Actor  Account
+}
+
+// PullRequestID — идентификатор PR в сервисе: github:owner/repo#42, gitlab:group/project!7
+func (e *Event) PullRequestID() string {
+	sep := "#"
+	if e.Provider == ProviderGitLab {
+		sep = "!"
+	}
+	return string(e.Provider) + ":" + e.Repository + sep + strconv.FormatInt(e.Number, 10)
+}
+
+// accountID — числовой идентификатор пользователя хостинга строкой; 0 — не передан
+func accountID(id int64) string {
+	if id == 0 {
+		return ""
+	}
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,91 @@
+package hosting
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+
+	"reviewtask/apperr"
+)
+
+type githubUser struct {
+	Login string `json:"login"`
+	ID    int64  `json:"id"`
+}
+
+func (u *githubUser) account() Account {
+	if u == nil {
+		return Account{}
+	}
+	return Account{Login: u.Login, ID: accountID(u.ID)}
+}
+
+type githubPullRequestEvent struct {
+	Action      string `json:"action"`
+	Number      int64  `json:"number"`
+	PullRequest struct {
+		Title    string      `json:"title"`
+		Draft    bool        `json:"draft"`
+		Merged   bool        `json:"merged"`
+		User     githubUser  `json:"user"`
+		MergedBy *githubUser `json:"merged_by"`
+	} `json:"pull_request"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+	Sender githubUser `json:"sender"`
+}
+
+// verifyGitHub проверяет заголовок X-Hub-Signature-256: sha256=<hex HMAC-SHA256 тела>
+func verifyGitHub(secret string, header http.Header, body []byte) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(expected), []byte(header.Get("X-Hub-Signature-256")))
+}
+
+// parseGitHub разбирает событие pull_request (заголовок X-GitHub-Event)
+func parseGitHub(header http.Header, body []byte) (*Event, error) {
+	if header.Get("X-GitHub-Event") != "pull_request" {
+		return nil, ErrUnsupportedEvent
+	}
+
+	var payload githubPullRequestEvent
+	if err := json.Unmarshal(body, &payload); err != nil || payload.Number == 0 || payload.Repository.FullName == "" {
+		return nil, apperr.ErrInvalidBody
+	}
+
+	event := &Event{
+		Provider:   ProviderGitHub,
+		Repository: payload.Repository.FullName,
+		Number:     payload.Number,
+		Title:      payload.PullRequest.Title,
+		Draft:      payload.PullRequest.Draft,
+		Author:     payload.PullRequest.User.account(),
+		Actor:      payload.Sender.account(),
+	}
+
+	switch payload.Action {
+	case "opened":
+		event.Action = ActionOpened
+	case "ready_for_review":
+		event.Action = ActionReadyForReview
+	case "reopened":
+		event.Action = ActionReopened
+	case "closed":
+		// GitHub присылает мердж как closed с merged: true
+		event.Action = ActionClosed
+		if payload.PullRequest.Merged {
+			event.Action = ActionMerged
+			if payload.PullRequest.MergedBy != nil {
+				event.Actor = payload.PullRequest.MergedBy.account()
+			}
+		}
+	default:
+		return nil, ErrUnsupportedEvent
+	}
+
+	return event, nil
+}
@@ -0,0 +1,99 @@
+package hosting
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+
+	"reviewtask/apperr"
+)
+
+type gitlabMergeRequestEvent struct {
+	ObjectKind string `json:"object_kind"`
+	User       struct {
+		ID       int64  `json:"id"`
+		Username string `json:"username"`
+	} `json:"user"`
+	Project struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+	} `json:"project"`
+	ObjectAttributes struct {
+		IID      int64  `json:"iid"`
+		Title    string `json:"title"`
+		Action   string `json:"action"`
+		Draft    bool   `json:"draft"`
+		AuthorID int64  `json:"author_id"`
+	} `json:"object_attributes"`
+	Changes struct {
+		Draft gitlabBoolChange `json:"draft"`
+		// Прежнее название флага в GitLab до 14.0
+		WorkInProgress gitlabBoolChange `json:"work_in_progress"`
+	} `json:"changes"`
+}
+
+// gitlabBoolChange — изменение флага в действии update; nil — флаг не менялся
+type gitlabBoolChange struct {
+	Previous *bool `json:"previous"`
+	Current  *bool `json:"current"`
+}
+
+// cleared сообщает, что флаг сняли
+func (c gitlabBoolChange) cleared() bool {
+	return c.Previous != nil && *c.Previous && c.Current != nil && !*c.Current
+}
+
+// verifyGitLab сравнивает заголовок X-Gitlab-Token с секретом: GitLab не подписывает
+// тело, а передаёт секретный токен как есть
+func verifyGitLab(secret string, header http.Header, body []byte) bool {
+	return subtle.ConstantTimeCompare([]byte(secret), []byte(header.Get("X-Gitlab-Token"))) == 1
+}
+
+// parseGitLab разбирает Merge Request Hook
+func parseGitLab(header http.Header, body []byte) (*Event, error) {
+	if header.Get("X-Gitlab-Event") != "Merge Request Hook" {
+		return nil, ErrUnsupportedEvent
+	}
+
+	var payload gitlabMergeRequestEvent
+	err := json.Unmarshal(body, &payload)
+	if err != nil || payload.ObjectKind != "merge_request" || payload.ObjectAttributes.IID == 0 || payload.Project.PathWithNamespace == "" {
+		return nil, apperr.ErrInvalidBody
+	}
+
+	attrs := payload.ObjectAttributes
+	actor := Account{Login: payload.User.Username, ID: accountID(payload.User.ID)}
+	event := &Event{
+		Provider:   ProviderGitLab,
+		Repository: payload.Project.PathWithNamespace,
+		Number:     attrs.IID,
+		Title:      attrs.Title,
+		Draft:      attrs.Draft,
+		// В вебхуке есть только числовой ID автора; логин известен, когда автор сам открыл MR
+		Author: Account{ID: accountID(attrs.AuthorID)},
+		Actor:  actor,
+	}
+
+	switch attrs.Action {
+	case "open":
+		event.Action = ActionOpened
+		if attrs.AuthorID == payload.User.ID {
+			event.Author = actor
+		}
+	case "close":
+		event.Action = ActionClosed
+	case "reopen":
+		event.Action = ActionReopened
+	case "merge":
+		event.Action = ActionMerged
+	case "update":
+		// Отдельного действия для снятия черновика у GitLab нет: это update с изменением draft
+		if !payload.Changes.Draft.cleared() && !payload.Changes.WorkInProgress.cleared() {
+			return nil, ErrUnsupportedEvent
+		}
+		event.Action = ActionReadyForReview
+	default:
+		return nil, ErrUnsupportedEvent
+	}
+
+	return event, nil
+}
@@ -0,0 +1,299 @@
+package hosting
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"reviewtask/service"
+)
+
+// Config — секреты вебхуков и соответствие пользователей хостинга пользователям сервиса
+type Config struct {
+	GitHubSecret string
+	GitLabToken  string
+	// UserMap: "github:octocat" -> "u1"
+	UserMap map[string]string
+	// MatchUserIDs разрешает считать пользователем сервиса того, чей user_id
+	// совпадает с логином на хостинге. По умолчанию выключено: логин на хостинге
+	// выбирает его владелец, и совпадение не доказывает, что это тот же человек.
+	MatchUserIDs bool
+}
+
+// ConfigFromEnv читает GITHUB_WEBHOOK_SECRET, GITLAB_WEBHOOK_TOKEN, GIT_USER_MAP
+// (provider:login=user_id через запятую) и GIT_MATCH_USER_IDS=true
+func ConfigFromEnv() (Config, error) {
+	userMap, err := ParseUserMap(os.Getenv("GIT_USER_MAP"))
+	if err != nil {
+		return Config{}, err
+	}
+	return Config{
+		GitHubSecret: os.Getenv("GITHUB_WEBHOOK_SECRET"),
+		GitLabToken:  os.Getenv("GITLAB_WEBHOOK_TOKEN"),
+		UserMap:      userMap,
+		MatchUserIDs: os.Getenv("GIT_MATCH_USER_IDS") == "true",
+	}, nil
+}
+
+// ParseUserMap разбирает список provider:login=user_id через запятую
+func ParseUserMap(spec string) (map[string]string, error) {
+	userMap := map[string]string{}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		account, userID, ok := strings.Cut(entry, "=")
+		provider, login, hasProvider := strings.Cut(account, ":")
+		// Логины сравниваются без учёта регистра, как во внешних учётных записях
+		provider, login = models.NormalizeIdentity(provider, login)
+		if !ok || !hasProvider || userID == "" || login == "" {
+			return nil, fmt.Errorf("user map entry must be provider:login=user_id, got %q", entry)
+		}
+		if Provider(provider) != ProviderGitHub && Provider(provider) != ProviderGitLab {
+			return nil, fmt.Errorf("user map entry %q: unknown provider %q", entry, provider)
+		}
+		userMap[provider+":"+login] = userID
+	}
+	return userMap, nil
+}
+
+// UserResolver находит пользователя сервиса по учётной записи хостинга.
+// Если пользователь не найден, возвращает apperr.ErrUserNotFound.
+type UserResolver interface {
+	ResolveUser(provider Provider, account Account) (string, error)
+}
+
+// Result — что сделал приёмник с событием
+type Result struct {
+	Result        string              `json:"result"`
+	PullRequestID string              `json:"pull_request_id"`
+	PR            *models.PullRequest `json:"pr,omitempty"`
+}
+
+const (
+	ResultCreated   = "created"
+	ResultReady     = "ready"
+	ResultClosed    = "closed"
+	ResultReopened  = "reopened"
+	ResultMerged    = "merged"
+	ResultUnchanged = "unchanged"
+	ResultIgnored   = "ignored"
+)
+
+// Receiver проверяет вебхуки хостингов и применяет их к PR сервиса
+type Receiver struct {
+	svc       *service.ReviewService
+	cfg       Config
+	resolvers []UserResolver
+}
+
+// NewReceiver создаёт приёмник. Пользователь ищется сначала в cfg.UserMap:
+// конфигурация задаётся оператором и важнее привязок через API. Затем — среди
+// внешних учётных записей (/users/addIdentity) и, только с cfg.MatchUserIDs,
+// среди пользователей сервиса с user_id, равным логину на хостинге.
+func NewReceiver(svc *service.ReviewService, cfg Config) *Receiver {
+	resolvers := []UserResolver{
+		staticUsers(cfg.UserMap),
+		identityUsers{svc: svc},
+	}
+	if cfg.MatchUserIDs {
+		resolvers = append(resolvers, serviceUsers{svc: svc})
+	}
+
+	return &Receiver{
+		svc:       svc,
+		cfg:       cfg,
+		resolvers: resolvers,
+	}
+}
+
+// Parse проверяет подпись вебхука и разбирает событие. Если для хостинга
+// не задан секрет, вебхуки не принимаются.
+func (r *Receiver) Parse(provider Provider, header http.Header, body []byte) (*Event, error) {
+	switch provider {
+	case ProviderGitHub:
+		if r.cfg.GitHubSecret == "" {
+			return nil, apperr.New(apperr.CodeForbidden, "github webhooks are not configured")
+		}
+		if !verifyGitHub(r.cfg.GitHubSecret, header, body) {
+			return nil, apperr.New(apperr.CodeUnauthorized, "invalid webhook signature")
+		}
+		return parseGitHub(header, body)
+	case ProviderGitLab:
+		if r.cfg.GitLabToken == "" {
+			return nil, apperr.New(apperr.CodeForbidden, "gitlab webhooks are not configured")
+		}
+		if !verifyGitLab(r.cfg.GitLabToken, header, body) {
+			return nil, apperr.New(apperr.CodeUnauthorized, "invalid webhook token")
+		}
+		return parseGitLab(header, body)
+	}
+	return nil, fmt.Errorf("unknown provider %q", provider)
+}
+
+// Handle применяет событие к PR. Повторная доставка того же события ничего не меняет.
+func (r *Receiver) Handle(event *Event) (*Result, error) {
+	prID := event.PullRequestID()
+	if event.Action == ActionOpened {
+		return r.open(event, prID)
+	}
+
+	pr, err := r.svc.GetPR(prID)
+	if err != nil {
+		return nil, err
+	}
+
+	actorID := r.actorID(event)
+	reason := string(event.Provider) + " webhook"
+
+	var result string
+	switch event.Action {
+	case ActionReadyForReview:
+		if pr.Status != models.StatusDraft {
+			return unchanged(pr), nil
+		}
+		pr, err = r.svc.MarkReady(prID, actorID, reason)
+		result = ResultReady
+	case ActionClosed:
+		if pr.Status == models.StatusClosed {
+			return unchanged(pr), nil
+		}
+		pr, err = r.svc.ClosePR(prID, actorID, reason)
+		result = ResultClosed
+	case ActionReopened:
+		if pr.Status.IsOpen() {
+			return unchanged(pr), nil
+		}
+		pr, err = r.svc.ReopenPR(prID, actorID, reason)
+		result = ResultReopened
+	case ActionMerged:
+		if pr.Status == models.StatusMerged {
+			return unchanged(pr), nil
+		}
+		// Мердж уже произошёл на хостинге: он записывается как факт,
+		// правила мерджа команды здесь не применяются
+		pr, err = r.svc.RecordMerge(prID, actorID, reason)
+		result = ResultMerged
+	default:
+		return nil, ErrUnsupportedEvent
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Result: result, PullRequestID: prID, PR: pr}, nil
+}
+
+func (r *Receiver) open(event *Event, prID string) (*Result, error) {
+	authorID, err := r.resolveUser(event.Provider, event.Author)
+	if err != nil {
+		if errors.Is(err, apperr.ErrUserNotFound) {
+			return nil, apperr.ErrAuthorNotFound
+		}
+		return nil, err
+	}
+
+	pr, err := r.svc.CreatePRWithReviewers(prID, event.Title, authorID, event.Draft)
+	if errors.Is(err, apperr.ErrPRExists) {
+		existing, getErr := r.svc.GetPR(prID)
+		if getErr != nil {
+			return nil, getErr
+		}
+		return unchanged(existing), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Result: ResultCreated, PullRequestID: prID, PR: pr}, nil
+}
+
+// actorID — пользователь сервиса, выполнивший действие; неизвестные
+// пользователи хостинга записываются в журнал как provider:login
+func (r *Receiver) actorID(event *Event) string {
+	if userID, err := r.resolveUser(event.Provider, event.Actor); err == nil {
+		return userID
+	}
+	if event.Actor.Login == "" {
+		return string(event.Provider)
+	}
+	return string(event.Provider) + ":" + event.Actor.Login
+}
+
+func (r *Receiver) resolveUser(provider Provider, account Account) (string, error) {
+	for _, resolver := range r.resolvers {
+		userID, err := resolver.ResolveUser(provider, account)
+		if err == nil {
+			return userID, nil
+		}
+		if !errors.Is(err, apperr.ErrUserNotFound) {
+			return "", err
+		}
+	}
+	return "", apperr.ErrUserNotFound
+}
+
+func unchanged(pr *models.PullRequest) *Result {
+	return &Result{Result: ResultUnchanged, PullRequestID: pr.PullRequestID, PR: pr}
+}
+
+// identityUsers находит пользователя по внешней учётной записи: логин ищется
+// под провайдером github или gitlab, числовой ID — под github-id или gitlab-id
+type identityUsers struct {
+	svc *service.ReviewService
+}
+
+func (s identityUsers) ResolveUser(provider Provider, account Account) (string, error) {
+	lookups := []struct{ provider, externalID string }{
+		{string(provider), account.Login},
+		{string(provider) + models.IdentityIDSuffix, account.ID},
+	}
+	for _, lookup := range lookups {
+		if lookup.externalID == "" {
+			continue
+		}
+
+		user, err := s.svc.ResolveUser(lookup.provider, lookup.externalID)
+		if errors.Is(err, apperr.ErrIdentityNotFound) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return user.UserID, nil
+	}
+	return "", apperr.ErrUserNotFound
+}
+
+// staticUsers — соответствие из конфигурации, ключ provider:login в нижнем регистре
+type staticUsers map[string]string
+
+func (m staticUsers) ResolveUser(provider Provider, account Account) (string, error) {
+	name, login := models.NormalizeIdentity(string(provider), account.Login)
+	if userID, ok := m[name+":"+login]; ok && login != "" {
+		return userID, nil
+	}
+	return "", apperr.ErrUserNotFound
+}
+
+// serviceUsers находит пользователя, чей user_id совпадает с логином на хостинге
+type serviceUsers struct {
+	svc *service.ReviewService
+}
+
+func (s serviceUsers) ResolveUser(_ Provider, account Account) (string, error) {
+	if account.Login == "" {
+		return "", apperr.ErrUserNotFound
+	}
+	user, err := s.svc.GetUser(account.Login)
+	if err != nil {
+		return "", err
+	}
+	return user.UserID, nil
+}
@@ -0,0 +1,377 @@
+package hosting
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"reviewtask/repo"
+	"reviewtask/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testGitHubSecret = "github-secret"
+	testGitLabToken  = "gitlab-token"
+)
+
+func newTestReceiver(t *testing.T) (*Receiver, *service.ReviewService) {
+	t.Helper()
+	return newTestReceiverWith(t, Config{})
+}
+
+// newTestReceiverWith дополняет cfg секретами и GIT_USER_MAP тестов
+func newTestReceiverWith(t *testing.T, cfg Config) (*Receiver, *service.ReviewService) {
+	t.Helper()
+	svc := service.NewReviewService(repo.NewMemoryStore())
+	members := []models.TeamMember{}
+	for _, id := range []string{"u1", "u2", "u3", "alice"} {
+		members = append(members, models.TeamMember{UserID: id, Username: id, IsActive: true})
+	}
+	require.NoError(t, svc.CreateTeam(&models.Team{TeamName: "backend", Members: members}))
+
+	userMap, err := ParseUserMap("github:octocat=u1, gitlab:jsmith=u2")
+	require.NoError(t, err)
+	cfg.GitHubSecret, cfg.GitLabToken, cfg.UserMap = testGitHubSecret, testGitLabToken, userMap
+	return NewReceiver(svc, cfg), svc
+}
+
+func fixture(t *testing.T, name string) []byte {
+	t.Helper()
+	body, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	return body
+}
+
+func githubHeader(event string, body []byte) http.Header {
+	mac := hmac.New(sha256.New, []byte(testGitHubSecret))
+	mac.Write(body)
+	header := http.Header{}
+	header.Set("X-GitHub-Event", event)
+	header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	return header
+}
+
+func gitlabHeader(token string) http.Header {
+	header := http.Header{}
+	header.Set("X-Gitlab-Event", "Merge Request Hook")
+	header.Set("X-Gitlab-Token", token)
+	return header
+}
+
+// deliver проверяет и применяет вебхук GitHub из фикстуры
+func deliver(t *testing.T, r *Receiver, name string) (*Result, error) {
+	t.Helper()
+	body := fixture(t, name)
+	event, err := r.Parse(ProviderGitHub, githubHeader("pull_request", body), body)
+	require.NoError(t, err)
+	return r.Handle(event)
+}
+
+func TestParseGitHubFixtures(t *testing.T) {
+	r, _ := newTestReceiver(t)
+
+	tests := []struct {
+		fixture string
+		action  Action
+		actor   string
+		draft   bool
+	}{
+		{"github_pull_request_opened.json", ActionOpened, "octocat", false},
+		{"github_pull_request_draft.json", ActionOpened, "alice", true},
+		{"github_pull_request_ready_for_review.json", ActionReadyForReview, "alice", false},
+		{"github_pull_request_closed.json", ActionClosed, "octocat", false},
+		{"github_pull_request_merged.json", ActionMerged, "hubot", false},
+		{"github_pull_request_reopened.json", ActionReopened, "octocat", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			body := fixture(t, tt.fixture)
+			event, err := r.Parse(ProviderGitHub, githubHeader("pull_request", body), body)
+			require.NoError(t, err)
+			assert.Equal(t, tt.action, event.Action)
+			assert.Equal(t, "acme/payments", event.Repository)
+			assert.Equal(t, tt.actor, event.Actor.Login)
+			assert.Equal(t, tt.draft, event.Draft)
+		})
+	}
+
+	body := fixture(t, "github_pull_request_opened.json")
+	event, err := r.Parse(ProviderGitHub, githubHeader("pull_request", body), body)
+	require.NoError(t, err)
+	assert.Equal(t, "github:acme/payments#42", event.PullRequestID())
+	assert.Equal(t, Account{Login: "octocat", ID: "583231"}, event.Author)
+
+	ping := fixture(t, "github_ping.json")
+	_, err = r.Parse(ProviderGitHub, githubHeader("ping", ping), ping)
+	assert.ErrorIs(t, err, ErrUnsupportedEvent)
+}
+
+func TestParseRejectsBadSignatures(t *testing.T) {
+	r, _ := newTestReceiver(t)
+	body := fixture(t, "github_pull_request_opened.json")
+
+	header := githubHeader("pull_request", body)
+	_, err := r.Parse(ProviderGitHub, header, append(body, ' '))
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeUnauthorized, ""))
+
+	header.Del("X-Hub-Signature-256")
+	_, err = r.Parse(ProviderGitHub, header, body)
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeUnauthorized, ""))
+
+	gitlab := fixture(t, "gitlab_merge_request_open.json")
+	_, err = r.Parse(ProviderGitLab, gitlabHeader("wrong"), gitlab)
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeUnauthorized, ""))
+
+	// Без секрета вебхуки хостинга не принимаются
+	unconfigured := NewReceiver(r.svc, Config{})
+	_, err = unconfigured.Parse(ProviderGitHub, githubHeader("pull_request", body), body)
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeForbidden, ""))
+	_, err = unconfigured.Parse(ProviderGitLab, gitlabHeader(""), gitlab)
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeForbidden, ""))
+}
+
+func TestReceiverGitHubLifecycle(t *testing.T) {
+	r, svc := newTestReceiver(t)
+
+	result, err := deliver(t, r, "github_pull_request_opened.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultCreated, result.Result)
+	assert.Equal(t, "github:acme/payments#42", result.PullRequestID)
+	assert.Equal(t, "u1", result.PR.AuthorID)
+	assert.Equal(t, "Add refund endpoint", result.PR.PullRequestName)
+	assert.Equal(t, models.StatusOpen, result.PR.Status)
+	assert.NotEmpty(t, result.PR.AssignedReviewers)
+
+	// Повторная доставка не создаёт PR заново
+	result, err = deliver(t, r, "github_pull_request_opened.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultUnchanged, result.Result)
+
+	result, err = deliver(t, r, "github_pull_request_closed.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultClosed, result.Result)
+	assert.Equal(t, models.StatusClosed, result.PR.Status)
+
+	result, err = deliver(t, r, "github_pull_request_reopened.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultReopened, result.Result)
+	assert.Equal(t, models.StatusReopened, result.PR.Status)
+
+	result, err = deliver(t, r, "github_pull_request_merged.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultMerged, result.Result)
+	assert.Equal(t, models.StatusMerged, result.PR.Status)
+
+	result, err = deliver(t, r, "github_pull_request_merged.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultUnchanged, result.Result)
+
+	// Неизвестный пользователь хостинга записан в журнал по логину
+	history, err := svc.GetPRHistory("github:acme/payments#42")
+	require.NoError(t, err)
+	last := history[len(history)-1]
+	assert.Equal(t, models.EventPRMerged, last.EventType)
+	assert.Equal(t, "github:hubot", last.ActorID)
+}
+
+func TestReceiverDraftAndFallbackResolution(t *testing.T) {
+	// alice нет в GIT_USER_MAP; совпадение логина с user_id само по себе не учитывается
+	r, _ := newTestReceiver(t)
+	_, err := deliver(t, r, "github_pull_request_draft.json")
+	assert.ErrorIs(t, err, apperr.ErrAuthorNotFound)
+
+	r, _ = newTestReceiverWith(t, Config{MatchUserIDs: true})
+	result, err := deliver(t, r, "github_pull_request_draft.json")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", result.PR.AuthorID)
+	assert.Equal(t, models.StatusDraft, result.PR.Status)
+	assert.Empty(t, result.PR.AssignedReviewers)
+
+	result, err = deliver(t, r, "github_pull_request_ready_for_review.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultReady, result.Result)
+	assert.Equal(t, models.StatusOpen, result.PR.Status)
+	assert.NotEmpty(t, result.PR.AssignedReviewers)
+}
+
+// deliverGitLab проверяет и применяет вебхук GitLab из фикстуры
+func deliverGitLab(t *testing.T, r *Receiver, name string) (*Result, error) {
+	t.Helper()
+	body := fixture(t, name)
+	event, err := r.Parse(ProviderGitLab, gitlabHeader(testGitLabToken), body)
+	require.NoError(t, err)
+	return r.Handle(event)
+}
+
+func TestReceiverGitLab(t *testing.T) {
+	r, svc := newTestReceiver(t)
+
+	body := fixture(t, "gitlab_merge_request_open.json")
+	event, err := r.Parse(ProviderGitLab, gitlabHeader(testGitLabToken), body)
+	require.NoError(t, err)
+	assert.Equal(t, "gitlab:acme/billing!7", event.PullRequestID())
+
+	result, err := r.Handle(event)
+	require.NoError(t, err)
+	assert.Equal(t, ResultCreated, result.Result)
+	assert.Equal(t, "u2", result.PR.AuthorID)
+
+	update := fixture(t, "gitlab_merge_request_update.json")
+	_, err = r.Parse(ProviderGitLab, gitlabHeader(testGitLabToken), update)
+	assert.ErrorIs(t, err, ErrUnsupportedEvent)
+
+	result, err = deliverGitLab(t, r, "gitlab_merge_request_close.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultClosed, result.Result)
+	assert.Equal(t, models.StatusClosed, result.PR.Status)
+
+	result, err = deliverGitLab(t, r, "gitlab_merge_request_close.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultUnchanged, result.Result)
+
+	result, err = deliverGitLab(t, r, "gitlab_merge_request_reopen.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultReopened, result.Result)
+	assert.Equal(t, models.StatusReopened, result.PR.Status)
+
+	history, err := svc.GetPRHistory("gitlab:acme/billing!7")
+	require.NoError(t, err)
+	last := history[len(history)-1]
+	assert.Equal(t, models.EventPRReopened, last.EventType)
+	assert.Equal(t, "u2", last.ActorID)
+	assert.Equal(t, "gitlab webhook", last.Reason)
+
+	// Мердж на хостинге уже произошёл: правила мерджа команды его не блокируют
+	minReviewers, requiredApprovals := 2, 1
+	_, err = svc.UpdateTeamSettings(&models.TeamSettingsPatch{
+		TeamName: "backend", MinReviewers: &minReviewers, MaxReviewers: &minReviewers, RequiredApprovals: &requiredApprovals,
+	})
+	require.NoError(t, err)
+
+	result, err = deliverGitLab(t, r, "gitlab_merge_request_merge.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultMerged, result.Result)
+	assert.Equal(t, models.StatusMerged, result.PR.Status)
+
+	result, err = deliverGitLab(t, r, "gitlab_merge_request_merge.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultUnchanged, result.Result)
+
+	// Логин u1 не сопоставлен пользователю: совпадение с user_id не учитывается
+	history, err = svc.GetPRHistory("gitlab:acme/billing!7")
+	require.NoError(t, err)
+	last = history[len(history)-1]
+	assert.Equal(t, models.EventPRMerged, last.EventType)
+	assert.Equal(t, "gitlab:u1", last.ActorID)
+	assert.Equal(t, "gitlab webhook", last.Reason)
+}
+
+func TestReceiverGitLabDraftReady(t *testing.T) {
+	r, _ := newTestReceiver(t)
+
+	result, err := deliverGitLab(t, r, "gitlab_merge_request_draft.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultCreated, result.Result)
+	assert.Equal(t, models.StatusDraft, result.PR.Status)
+	assert.Empty(t, result.PR.AssignedReviewers)
+
+	// Снятие черновика приходит как update с changes.draft: true -> false
+	body := fixture(t, "gitlab_merge_request_ready.json")
+	event, err := r.Parse(ProviderGitLab, gitlabHeader(testGitLabToken), body)
+	require.NoError(t, err)
+	assert.Equal(t, ActionReadyForReview, event.Action)
+
+	// Старые версии GitLab присылают то же изменение как work_in_progress
+	legacy := bytes.Replace(body, []byte(`"draft": {`), []byte(`"work_in_progress": {`), 1)
+	event, err = r.Parse(ProviderGitLab, gitlabHeader(testGitLabToken), legacy)
+	require.NoError(t, err)
+	assert.Equal(t, ActionReadyForReview, event.Action)
+
+	result, err = r.Handle(event)
+	require.NoError(t, err)
+	assert.Equal(t, ResultReady, result.Result)
+	assert.Equal(t, models.StatusOpen, result.PR.Status)
+	assert.NotEmpty(t, result.PR.AssignedReviewers)
+
+	result, err = deliverGitLab(t, r, "gitlab_merge_request_ready.json")
+	require.NoError(t, err)
+	assert.Equal(t, ResultUnchanged, result.Result)
+}
+
+func TestReceiverUnknownAuthor(t *testing.T) {
+	r, _ := newTestReceiver(t)
+	r.resolvers = []UserResolver{staticUsers{}}
+
+	_, err := deliver(t, r, "github_pull_request_opened.json")
+	assert.ErrorIs(t, err, apperr.ErrAuthorNotFound)
+
+	_, err = deliver(t, r, "github_pull_request_closed.json")
+	assert.ErrorIs(t, err, apperr.ErrPRNotFound)
+}
+
+func TestParseUserMap(t *testing.T) {
+	userMap, err := ParseUserMap("")
+	require.NoError(t, err)
+	assert.Empty(t, userMap)
+
+	for _, spec := range []string{"octocat=u1", "github:octocat", "bitbucket:octocat=u1", "github:=u1"} {
+		_, err := ParseUserMap(spec)
+		assert.Error(t, err, spec)
+	}
+
+	// Логины сравниваются без учёта регистра, как во внешних учётных записях
+	userMap, err = ParseUserMap("GitHub:OctoCat=u1")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"github:octocat": "u1"}, userMap)
+
+	for _, login := range []string{"octocat", "OCTOCAT", "OctoCat"} {
+		userID, err := staticUsers(userMap).ResolveUser(ProviderGitHub, Account{Login: login})
+		require.NoError(t, err, login)
+		assert.Equal(t, "u1", userID)
+	}
+}
+
+func TestReceiverResolvesUserMapFirst(t *testing.T) {
+	r, svc := newTestReceiver(t)
+
+	// GIT_USER_MAP (github:octocat=u1) важнее привязанной учётной записи
+	_, err := svc.AddUserIdentity(&models.UserIdentity{UserID: "u3", Provider: "github", ExternalID: "octocat"})
+	require.NoError(t, err)
+	// Логины вне GIT_USER_MAP находятся по внешним учётным записям
+	_, err = svc.AddUserIdentity(&models.UserIdentity{UserID: "alice", Provider: "github", ExternalID: "hubot"})
+	require.NoError(t, err)
+	// Логин из цифр не совпадает с числовым ID: ID привязываются под gitlab-id
+	_, err = svc.AddUserIdentity(&models.UserIdentity{UserID: "u3", Provider: "gitlab", ExternalID: "64"})
+	require.NoError(t, err)
+	_, err = svc.AddUserIdentity(&models.UserIdentity{UserID: "alice", Provider: "gitlab-id", ExternalID: "64"})
+	require.NoError(t, err)
+
+	result, err := deliver(t, r, "github_pull_request_opened.json")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", result.PR.AuthorID)
+
+	_, err = deliver(t, r, "github_pull_request_merged.json")
+	require.NoError(t, err)
+	history, err := svc.GetPRHistory("github:acme/payments#42")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", history[len(history)-1].ActorID)
+
+	_, err = deliverGitLab(t, r, "gitlab_merge_request_open.json")
+	require.NoError(t, err)
+	_, err = deliverGitLab(t, r, "gitlab_merge_request_merge.json")
+	require.NoError(t, err)
+
+	history, err = svc.GetPRHistory("gitlab:acme/billing!7")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", history[len(history)-1].ActorID)
+}
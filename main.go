@@ -1,14 +1,35 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
+	"reviewtask/auth"
 	"reviewtask/database"
 	"reviewtask/handlers"
+	"reviewtask/hosting"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			os.Exit(runMigrate(os.Args[2:]))
+		case "token":
+			os.Exit(runToken(os.Args[2:]))
+		}
+	}
+
+	authenticator, err := auth.FromEnv()
+	if err != nil {
+		log.Fatalf("Auth is not configured: %v (set AUTH_TOKEN_SECRET or AUTH_API_KEYS, or AUTH_DISABLED=true for local development)", err)
+	}
+	if authenticator.IsDisabled() {
+		log.Println("WARNING: authentication is disabled, every request is treated as admin")
+	}
+
 	db := database.InitDB()
 	defer db.DB.Close()
 
@@ -16,32 +37,36 @@ func main() {
 
 	database.InitTestData(app.Repo)
 
-	r := setupRouter(app)
+	if err := startEventPipeline(context.Background(), app.Repo); err != nil {
+		log.Fatalf("Failed to start event publishing: %v", err)
+	}
+
+	hostingConfig, err := hosting.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Git hosting webhooks are misconfigured: %v", err)
+	}
+
+	r := setupRouter(app, authenticator, hosting.NewReceiver(app.Service, hostingConfig))
 
 	port := "8080"
 	log.Printf("Server starting on :%s...", port)
 	log.Fatal(r.Run(":" + port))
 }
 
-func setupRouter(app *handlers.App) *gin.Engine {
+func setupRouter(app *handlers.App, authenticator *auth.Authenticator, receiver *hosting.Receiver) *gin.Engine {
 	r := gin.Default()
+	r.Use(handlers.ErrorHandler())
 
-	// Health checks
+	// Health check открыт для балансировщика
 	r.GET("/health", handlers.HealthHandler(app.Repo.DB))
-	r.GET("/tables", handlers.TablesHandler(app.Repo.DB))
-
-	// Teams endpoints
-	r.POST("/team/add", app.CreateTeamHandler)
-	r.GET("/team/get", app.GetTeamHandler)
 
-	// Users endpoints
-	r.POST("/users/setIsActive", app.SetUserActiveHandler)
-	r.GET("/users/getReview", app.GetUserReviewHandler)
+	// Вебхуки Git-хостингов проверяются по подписи хостинга
+	r.POST("/integrations/github", handlers.HostingWebhookHandler(receiver, hosting.ProviderGitHub))
+	r.POST("/integrations/gitlab", handlers.HostingWebhookHandler(receiver, hosting.ProviderGitLab))
 
-	// Pull Request endpoints
-	r.POST("/pullRequest/create", app.CreatePRHandler)
-	r.POST("/pullRequest/merge", app.MergePRHandler)
-	r.POST("/pullRequest/reassign", app.ReassignReviewerHandler)
+	api := r.Group("/", handlers.Authenticate(authenticator))
+	api.GET("/tables", handlers.RequireRoles(auth.RoleAdmin), handlers.TablesHandler(app.Repo.DB))
+	app.RegisterRoutes(api)
 
 	return r
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"reviewtask/database"
+)
+
+const migrateUsage = `usage: review-service migrate <command>
+
+commands:
+  up             apply all pending migrations
+  down [N]       revert the last N migrations (default 1)
+  status         show applied and pending migrations
+  force VERSION  mark migrations up to VERSION as applied without running them`
+
+// runMigrate выполняет подкоманду migrate и возвращает код выхода
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	db := database.OpenDB()
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Print(err)
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, m := range applied {
+			fmt.Printf("applied %03d-%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+
+	case "down":
+		steps, ok := parseMigrateArg(args, 1)
+		if !ok || steps < 1 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		reverted, err := migrator.Down(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %03d-%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			log.Print(err)
+			return 1
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.AppliedAt != nil {
+				state = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if !s.Known {
+				state += " (unknown to this binary)"
+			}
+			fmt.Printf("%03d  %-28s %s\n", s.Version, s.Name, state)
+		}
+
+	case "force":
+		version, ok := parseMigrateArg(args, -1)
+		if !ok || version < 0 {
+			fmt.Fprintln(os.Stderr, migrateUsage)
+			return 2
+		}
+		if err := migrator.Force(version); err != nil {
+			log.Print(err)
+			return 1
+		}
+		fmt.Printf("schema version set to %d\n", version)
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
+
+// parseMigrateArg читает числовой аргумент подкоманды; defaultValue < 0 — аргумент обязателен
+func parseMigrateArg(args []string, defaultValue int) (int, bool) {
+	if len(args) < 2 {
+		return defaultValue, defaultValue >= 0
+	}
+	if len(args) > 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(args[1])
+	return n, err == nil
+}
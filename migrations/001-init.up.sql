@@ -1,7 +1,3 @@
-DROP TABLE IF EXISTS pull_requests CASCADE;
-DROP TABLE IF EXISTS users CASCADE;
-DROP TABLE IF EXISTS teams CASCADE;
-
 CREATE TABLE teams (
     team_name VARCHAR(255) PRIMARY KEY,
     created_at TIMESTAMP DEFAULT NOW()
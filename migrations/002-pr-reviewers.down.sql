@@ -0,0 +1,21 @@
+ALTER TABLE IF EXISTS pull_requests ADD COLUMN IF NOT EXISTS assigned_reviewers TEXT DEFAULT '';
+
+-- Возвращаем ревьюеров в строковый столбец (таблицы может не быть при первичной инициализации)
+DO $$
+BEGIN
+    IF to_regclass('pr_reviewers') IS NOT NULL THEN
+        UPDATE pull_requests pr
+        SET assigned_reviewers = sub.reviewers
+        FROM (
+            SELECT pull_request_id, string_agg(user_id, ',' ORDER BY assigned_at, user_id) AS reviewers
+            FROM pr_reviewers
+            WHERE state = 'ASSIGNED'
+            GROUP BY pull_request_id
+        ) sub
+        WHERE pr.pull_request_id = sub.pull_request_id;
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_pr_reviewers_pr_state;
+DROP INDEX IF EXISTS idx_pr_reviewers_user_state;
+DROP TABLE IF EXISTS pr_reviewers;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS pr_reviewers (
+    pull_request_id VARCHAR(255) NOT NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id),
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    state VARCHAR(50) NOT NULL DEFAULT 'ASSIGNED',
+    PRIMARY KEY (pull_request_id, user_id)
+);
+
+-- Переносим ревьюеров из строкового столбца assigned_reviewers
+INSERT INTO pr_reviewers (pull_request_id, user_id, assigned_at)
+SELECT pr.pull_request_id, r.user_id, pr.created_at
+FROM pull_requests pr
+CROSS JOIN LATERAL unnest(string_to_array(pr.assigned_reviewers, ',')) AS r(user_id)
+WHERE r.user_id <> ''
+  AND EXISTS (SELECT 1 FROM users u WHERE u.user_id = r.user_id)
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE pull_requests DROP COLUMN IF EXISTS assigned_reviewers;
+
+-- Индексы для выборки "что я ревьюю" и списка ревьюеров PR
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_user_state ON pr_reviewers(user_id, state);
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_pr_state ON pr_reviewers(pull_request_id, state);
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS teams DROP COLUMN IF EXISTS reviewer_strategy;
@@ -0,0 +1 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS reviewer_strategy VARCHAR(50) NOT NULL DEFAULT 'random';
@@ -0,0 +1 @@
+ALTER TABLE teams ADD COLUMN IF NOT EXISTS reviewer_strategy_params JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS team_settings (
+    team_name VARCHAR(255) PRIMARY KEY REFERENCES teams(team_name) ON DELETE CASCADE,
+    min_reviewers INT NOT NULL DEFAULT 1,
+    max_reviewers INT NOT NULL DEFAULT 2,
+    allow_cross_team BOOLEAN NOT NULL DEFAULT FALSE,
+    fail_on_insufficient BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP DEFAULT NOW(),
+    CHECK (min_reviewers >= 0 AND max_reviewers >= min_reviewers)
+);
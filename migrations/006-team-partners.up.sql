@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS team_partners (
+    team_name VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    partner_team VARCHAR(255) NOT NULL REFERENCES teams(team_name) ON DELETE CASCADE,
+    -- порядок, в котором команды-партнёры используются для добора ревьюеров
+    priority INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (team_name, partner_team),
+    CHECK (team_name <> partner_team)
+);
+
+-- Ревьюер назначен из команды-партнёра или другой команды, а не из команды автора
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS is_fallback BOOLEAN NOT NULL DEFAULT FALSE;
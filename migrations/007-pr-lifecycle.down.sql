@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS pull_requests DROP COLUMN IF EXISTS closed_at;
@@ -0,0 +1,6 @@
+ALTER TABLE IF EXISTS team_settings DROP COLUMN IF EXISTS block_on_changes_requested;
+ALTER TABLE IF EXISTS team_settings DROP COLUMN IF EXISTS required_approvals;
+
+ALTER TABLE IF EXISTS pr_reviewers DROP COLUMN IF EXISTS verdict_at;
+ALTER TABLE IF EXISTS pr_reviewers DROP COLUMN IF EXISTS verdict_comment;
+ALTER TABLE IF EXISTS pr_reviewers DROP COLUMN IF EXISTS verdict;
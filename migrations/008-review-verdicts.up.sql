@@ -0,0 +1,6 @@
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS verdict VARCHAR(50) NULL;
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS verdict_comment TEXT NULL;
+ALTER TABLE pr_reviewers ADD COLUMN IF NOT EXISTS verdict_at TIMESTAMP NULL;
+
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS required_approvals INT NOT NULL DEFAULT 0;
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS block_on_changes_requested BOOLEAN NOT NULL DEFAULT FALSE;
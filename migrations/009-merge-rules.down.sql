@@ -0,0 +1,2 @@
+ALTER TABLE IF EXISTS team_settings DROP COLUMN IF EXISTS disallow_self_merge;
+ALTER TABLE IF EXISTS team_settings DROP COLUMN IF EXISTS require_reviewer;
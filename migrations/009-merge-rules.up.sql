@@ -0,0 +1,2 @@
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS require_reviewer BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE team_settings ADD COLUMN IF NOT EXISTS disallow_self_merge BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,16 @@
+-- Журнал событий: только добавление, записи не изменяются
+CREATE TABLE IF NOT EXISTS pr_events (
+    id BIGSERIAL PRIMARY KEY,
+    -- NULL для событий, не относящихся к конкретному PR (например, смена активности)
+    pull_request_id VARCHAR(255) NULL REFERENCES pull_requests(pull_request_id) ON DELETE CASCADE,
+    event_type VARCHAR(50) NOT NULL,
+    -- NULL, если действие выполнено сервисом автоматически
+    actor_id VARCHAR(255) NULL,
+    user_id VARCHAR(255) NULL,
+    previous_user_id VARCHAR(255) NULL,
+    reason TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS idx_pr_events_pr ON pr_events(pull_request_id, id);
+CREATE INDEX IF NOT EXISTS idx_pr_events_user ON pr_events(user_id);
@@ -0,0 +1,4 @@
+-- Индексы для выборок статистики по временному окну
+CREATE INDEX IF NOT EXISTS idx_pr_reviewers_assigned_at ON pr_reviewers(assigned_at);
+CREATE INDEX IF NOT EXISTS idx_pr_merged_at ON pull_requests(merged_at) WHERE merged_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_pr_events_type_created ON pr_events(event_type, created_at);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_absences_active;
+DROP TABLE IF EXISTS user_absences;
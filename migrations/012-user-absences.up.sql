@@ -0,0 +1,16 @@
+-- Границы отсутствий приходят со смещением клиента и сравниваются с NOW(),
+-- поэтому хранятся с часовым поясом
+CREATE TABLE IF NOT EXISTS user_absences (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    reason TEXT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    cancelled_at TIMESTAMPTZ NULL,
+    CHECK (ends_at > starts_at)
+);
+
+-- Поиск текущих отсутствий при подборе ревьюеров
+CREATE INDEX IF NOT EXISTS idx_user_absences_active ON user_absences(user_id, starts_at, ends_at)
+    WHERE cancelled_at IS NULL;
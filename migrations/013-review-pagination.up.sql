@@ -0,0 +1,2 @@
+-- Постраничная выдача PR по (created_at, pull_request_id)
+CREATE INDEX IF NOT EXISTS idx_pr_created_id ON pull_requests(created_at, pull_request_id);
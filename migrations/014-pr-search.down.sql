@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_pr_author_created;
+DROP INDEX IF EXISTS idx_pr_merged_id;
+DROP INDEX IF EXISTS idx_pr_name_trgm;
@@ -0,0 +1,6 @@
+-- Поиск PR по подстроке названия (ILIKE) и сортировка по времени мерджа
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX IF NOT EXISTS idx_pr_name_trgm ON pull_requests USING gin (pull_request_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_pr_merged_id ON pull_requests(merged_at, pull_request_id) WHERE merged_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_pr_author_created ON pull_requests(author_id, created_at, pull_request_id);
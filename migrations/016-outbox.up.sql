@@ -0,0 +1,19 @@
+-- Transactional outbox: событие записывается в транзакции изменения
+-- и удаляется после публикации во все приёмники
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    idempotency_key VARCHAR(255) NOT NULL UNIQUE,
+    event_id BIGINT NOT NULL,
+    event_type VARCHAR(50) NOT NULL,
+    team_name VARCHAR(255) NULL,
+    payload JSONB NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_error TEXT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_due ON outbox_events(next_attempt_at, id);
+
+-- Повторная публикация события не ставит вторую доставку тому же подписчику
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event ON webhook_deliveries(subscription_id, event_id);
@@ -0,0 +1,8 @@
+// Package migrations содержит SQL-миграции схемы, встроенные в бинарник.
+// Файлы называются NNN-имя.up.sql и NNN-имя.down.sql, где NNN — версия схемы.
+package migrations
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS
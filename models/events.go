@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type EventType string
+
+const (
+	EventPRCreated          EventType = "PR_CREATED"
+	EventReviewerAssigned   EventType = "REVIEWER_ASSIGNED"
+	EventReviewerReassigned EventType = "REVIEWER_REASSIGNED"
+	EventReviewSubmitted    EventType = "REVIEW_SUBMITTED"
+	EventPRReady            EventType = "PR_READY"
+	EventPRClosed           EventType = "PR_CLOSED"
+	EventPRReopened         EventType = "PR_REOPENED"
+	EventPRMerged           EventType = "PR_MERGED"
+	EventUserActivated      EventType = "USER_ACTIVATED"
+	EventUserDeactivated    EventType = "USER_DEACTIVATED"
+	EventUserTeamChanged    EventType = "USER_TEAM_CHANGED"
+)
+
+// PREvent — запись журнала аудита.
+// ActorID — кто выполнил действие (пусто для автоматических действий сервиса),
+// UserID — затронутый пользователь, PreviousUserID — заменённый ревьюер.
+type PREvent struct {
+	ID             int64     `json:"id" db:"id"`
+	PullRequestID  string    `json:"pull_request_id,omitempty" db:"pull_request_id"`
+	EventType      EventType `json:"event_type" db:"event_type"`
+	ActorID        string    `json:"actor_id,omitempty" db:"actor_id"`
+	UserID         string    `json:"user_id,omitempty" db:"user_id"`
+	PreviousUserID string    `json:"previous_user_id,omitempty" db:"previous_user_id"`
+	Reason         string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt      time.Time `json:"createdAt" db:"created_at"`
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -12,8 +13,31 @@ type User struct {
 }
 
 type Team struct {
-	TeamName string       `json:"team_name" db:"team_name"`
-	Members  []TeamMember `json:"members" db:"-"`
+	TeamName         string           `json:"team_name" db:"team_name"`
+	ReviewerStrategy ReviewerStrategy `json:"reviewer_strategy,omitempty" db:"reviewer_strategy"`
+	StrategyParams   StrategyParams   `json:"strategy_params" db:"reviewer_strategy_params"`
+	PartnerTeams     []string         `json:"partner_teams,omitempty" db:"-"`
+	Members          []TeamMember     `json:"members" db:"-"`
+}
+
+// ReviewerStrategy определяет, как выбираются ревьюеры среди кандидатов команды
+type ReviewerStrategy string
+
+const (
+	StrategyRandom      ReviewerStrategy = "random"
+	StrategyRoundRobin  ReviewerStrategy = "round_robin"
+	StrategyLeastLoaded ReviewerStrategy = "least_loaded"
+	StrategyWeighted    ReviewerStrategy = "weighted"
+	StrategyExpertise   ReviewerStrategy = "expertise"
+)
+
+// StrategyParams — параметры стратегии выбора ревьюеров
+type StrategyParams struct {
+	// Weights — веса участников для стратегии weighted (по умолчанию 1)
+	Weights map[string]int `json:"weights,omitempty"`
+	// Expertise — ключевые слова участников для стратегии expertise,
+	// сопоставляются с названием PR
+	Expertise map[string][]string `json:"expertise,omitempty"`
 }
 
 type TeamMember struct {
@@ -25,23 +49,243 @@ type TeamMember struct {
 type PRStatus string
 
 const (
-	StatusOpen   PRStatus = "OPEN"
-	StatusMerged PRStatus = "MERGED"
+	StatusDraft    PRStatus = "DRAFT"
+	StatusOpen     PRStatus = "OPEN"
+	StatusReopened PRStatus = "REOPENED"
+	StatusClosed   PRStatus = "CLOSED"
+	StatusMerged   PRStatus = "MERGED"
 )
 
+// IsOpen сообщает, ожидает ли PR ревью
+func (s PRStatus) IsOpen() bool {
+	return s == StatusOpen || s == StatusReopened
+}
+
 type PullRequest struct {
 	PullRequestID     string     `json:"pull_request_id" db:"pull_request_id"`
 	PullRequestName   string     `json:"pull_request_name" db:"pull_request_name"`
 	AuthorID          string     `json:"author_id" db:"author_id"`
 	Status            PRStatus   `json:"status" db:"status"`
 	AssignedReviewers []string   `json:"assigned_reviewers" db:"-"`
+	FallbackReviewers []string   `json:"fallback_reviewers,omitempty" db:"-"`
+	Reviews           []Review   `json:"reviews,omitempty" db:"-"`
 	CreatedAt         time.Time  `json:"createdAt" db:"created_at"`
 	MergedAt          *time.Time `json:"mergedAt,omitempty" db:"merged_at"`
+	ClosedAt          *time.Time `json:"closedAt,omitempty" db:"closed_at"`
+}
+
+type ReviewVerdict string
+
+const (
+	VerdictApproved         ReviewVerdict = "APPROVED"
+	VerdictChangesRequested ReviewVerdict = "CHANGES_REQUESTED"
+	VerdictCommented        ReviewVerdict = "COMMENTED"
+)
+
+// Review — последний вердикт назначенного ревьюера
+type Review struct {
+	UserID      string        `json:"user_id" db:"user_id"`
+	Verdict     ReviewVerdict `json:"verdict" db:"verdict"`
+	Comment     string        `json:"comment,omitempty" db:"verdict_comment"`
+	SubmittedAt time.Time     `json:"submittedAt" db:"verdict_at"`
 }
 
 type PullRequestShort struct {
-	PullRequestID   string   `json:"pull_request_id"`
-	PullRequestName string   `json:"pull_request_name"`
-	AuthorID        string   `json:"author_id"`
-	Status          PRStatus `json:"status"`
+	PullRequestID   string     `json:"pull_request_id"`
+	PullRequestName string     `json:"pull_request_name"`
+	AuthorID        string     `json:"author_id"`
+	Status          PRStatus   `json:"status"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	MergedAt        *time.Time `json:"mergedAt,omitempty"`
+}
+
+// PRSortField — поле сортировки списка PR
+type PRSortField string
+
+const (
+	SortByCreatedAt PRSortField = "created_at"
+	// SortByMergedAt — по времени мерджа; в выборку попадают только смердженные PR
+	SortByMergedAt PRSortField = "merged_at"
+)
+
+// PRFilter — выборка PR для /pullRequest/list и /users/getReview.
+// Пустые поля не ограничивают выборку. TeamName — команда автора,
+// ReviewerID — ревьюер, назначенный сейчас, NameContains — подстрока
+// названия без учёта регистра; окна [From, To) применяются к created_at и merged_at.
+type PRFilter struct {
+	Statuses     []PRStatus
+	AuthorID     string
+	TeamName     string
+	ReviewerID   string
+	NameContains string
+	CreatedFrom  *time.Time
+	CreatedTo    *time.Time
+	MergedFrom   *time.Time
+	MergedTo     *time.Time
+	SortBy       PRSortField
+	Order        SortOrder
+	Cursor       *PageCursor
+	Limit        int
+}
+
+// SortKey — значение поля сортировки PR для курсора страницы
+func (pr PullRequestShort) SortKey(sortBy PRSortField) time.Time {
+	if sortBy == SortByMergedAt && pr.MergedAt != nil {
+		return *pr.MergedAt
+	}
+	return pr.CreatedAt
+}
+
+// PRPage — страница списка PR; Total — число PR под фильтром без учёта курсора
+type PRPage struct {
+	PullRequests []PullRequestShort `json:"pull_requests"`
+	Total        int                `json:"total"`
+	NextCursor   string             `json:"next_cursor,omitempty"`
+}
+
+// TeamSettings — политика назначения ревьюеров и мерджа команды.
+// RequiredApprovals, BlockOnChangesRequested, RequireReviewer и DisallowSelfMerge
+// проверяются при мердже (RequiredApprovals = 0 — одобрения не требуются).
+type TeamSettings struct {
+	TeamName                string `json:"team_name" db:"team_name"`
+	MinReviewers            int    `json:"min_reviewers" db:"min_reviewers"`
+	MaxReviewers            int    `json:"max_reviewers" db:"max_reviewers"`
+	AllowCrossTeam          bool   `json:"allow_cross_team" db:"allow_cross_team"`
+	FailOnInsufficient      bool   `json:"fail_on_insufficient" db:"fail_on_insufficient"`
+	RequiredApprovals       int    `json:"required_approvals" db:"required_approvals"`
+	BlockOnChangesRequested bool   `json:"block_on_changes_requested" db:"block_on_changes_requested"`
+	RequireReviewer         bool   `json:"require_reviewer" db:"require_reviewer"`
+	DisallowSelfMerge       bool   `json:"disallow_self_merge" db:"disallow_self_merge"`
+}
+
+// TeamSettingsPatch — изменение настроек команды: поля, которых нет в запросе,
+// сохраняют текущие значения
+type TeamSettingsPatch struct {
+	TeamName                string `json:"team_name"`
+	MinReviewers            *int   `json:"min_reviewers"`
+	MaxReviewers            *int   `json:"max_reviewers"`
+	AllowCrossTeam          *bool  `json:"allow_cross_team"`
+	FailOnInsufficient      *bool  `json:"fail_on_insufficient"`
+	RequiredApprovals       *int   `json:"required_approvals"`
+	BlockOnChangesRequested *bool  `json:"block_on_changes_requested"`
+	RequireReviewer         *bool  `json:"require_reviewer"`
+	DisallowSelfMerge       *bool  `json:"disallow_self_merge"`
+}
+
+// ApplyTo переносит заданные поля в settings
+func (p *TeamSettingsPatch) ApplyTo(settings *TeamSettings) {
+	setInt := func(dst *int, src *int) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setBool := func(dst *bool, src *bool) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+
+	setInt(&settings.MinReviewers, p.MinReviewers)
+	setInt(&settings.MaxReviewers, p.MaxReviewers)
+	setBool(&settings.AllowCrossTeam, p.AllowCrossTeam)
+	setBool(&settings.FailOnInsufficient, p.FailOnInsufficient)
+	setInt(&settings.RequiredApprovals, p.RequiredApprovals)
+	setBool(&settings.BlockOnChangesRequested, p.BlockOnChangesRequested)
+	setBool(&settings.RequireReviewer, p.RequireReviewer)
+	setBool(&settings.DisallowSelfMerge, p.DisallowSelfMerge)
+}
+
+// DefaultTeamSettings возвращает настройки команды, для которой они не сохранены
+func DefaultTeamSettings(teamName string) *TeamSettings {
+	return &TeamSettings{
+		TeamName:     teamName,
+		MinReviewers: 1,
+		MaxReviewers: 2,
+	}
+}
+
+// ReassignmentResult — итог переназначения одного ревью при деактивации пользователя
+type ReassignmentResult struct {
+	PullRequestID string `json:"pull_request_id"`
+	OldUserID     string `json:"old_user_id"`
+	NewUserID     string `json:"new_user_id,omitempty"`
+	Reassigned    bool   `json:"reassigned"`
+	Error         string `json:"error,omitempty"`
+}
+
+// DeactivationReport — результат массовой деактивации
+type DeactivationReport struct {
+	Deactivated   []string             `json:"deactivated"`
+	Reassignments []ReassignmentResult `json:"reassignments"`
+}
+
+// MembershipChange — результат изменения состава команды: добавления,
+// исключения или перевода пользователя в другую команду
+type MembershipChange struct {
+	User             *User                `json:"user"`
+	PreviousTeamName string               `json:"previous_team_name,omitempty"`
+	Reassignments    []ReassignmentResult `json:"reassignments"`
+}
+
+// TeamSyncResult — изменения, внесённые синхронизацией состава команды
+type TeamSyncResult struct {
+	TeamName      string               `json:"team_name"`
+	CreatedTeam   bool                 `json:"created_team"`
+	Added         []string             `json:"added"`
+	Moved         []string             `json:"moved"`
+	Updated       []string             `json:"updated"`
+	Deactivated   []string             `json:"deactivated"`
+	Unchanged     []string             `json:"unchanged"`
+	Conflicts     []string             `json:"conflicts"`
+	Reassignments []ReassignmentResult `json:"reassignments"`
+}
+
+// Absence — период отсутствия пользователя; пока он длится, пользователь
+// не назначается ревьюером
+type Absence struct {
+	ID          int64      `json:"absence_id" db:"id"`
+	UserID      string     `json:"user_id" db:"user_id"`
+	StartsAt    time.Time  `json:"startsAt" db:"starts_at"`
+	EndsAt      time.Time  `json:"endsAt" db:"ends_at"`
+	Reason      string     `json:"reason,omitempty" db:"reason"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	CancelledAt *time.Time `json:"cancelledAt,omitempty" db:"cancelled_at"`
+}
+
+// UserIdentity — учётная запись пользователя во внешней системе: логин на
+// Git-хостинге, email, ник в чате. Пара provider + external_id принадлежит
+// не более чем одному пользователю.
+type UserIdentity struct {
+	Provider   string    `json:"provider" db:"provider"`
+	ExternalID string    `json:"external_id" db:"external_id"`
+	UserID     string    `json:"user_id" db:"user_id"`
+	CreatedAt  time.Time `json:"createdAt" db:"created_at"`
+}
+
+// Провайдеры учётных записей Git-хостинга. По ним вебхуки хостинга определяют
+// автора и исполнителя действий с PR. Логины и числовые ID аккаунтов хранятся
+// под разными провайдерами: логин из цифр не должен совпасть с чужим ID.
+const (
+	IdentityGitHub   = "github"
+	IdentityGitLab   = "gitlab"
+	IdentityGitHubID = IdentityGitHub + IdentityIDSuffix
+	IdentityGitLabID = IdentityGitLab + IdentityIDSuffix
+
+	// IdentityIDSuffix отличает провайдер числовых ID от провайдера логинов
+	IdentityIDSuffix = "-id"
+)
+
+// NormalizeIdentity приводит провайдера и внешний идентификатор к виду, в котором
+// они хранятся и сравниваются: без пробелов по краям и в нижнем регистре
+func NormalizeIdentity(provider, externalID string) (string, string) {
+	return strings.ToLower(strings.TrimSpace(provider)), strings.ToLower(strings.TrimSpace(externalID))
+}
+
+// IsHostingIdentity сообщает, относится ли provider к Git-хостингу
+func IsHostingIdentity(provider string) bool {
+	switch strings.ToLower(strings.TrimSpace(provider)) {
+	case IdentityGitHub, IdentityGitLab, IdentityGitHubID, IdentityGitLabID:
+		return true
+	}
+	return false
 }
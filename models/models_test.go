@@ -43,5 +43,24 @@ func TestModels(t *testing.T) {
 	t.Run("PRStatus constants", func(t *testing.T) {
 		assert.Equal(t, PRStatus("OPEN"), StatusOpen)
 		assert.Equal(t, PRStatus("MERGED"), StatusMerged)
+		assert.Equal(t, PRStatus("DRAFT"), StatusDraft)
+		assert.Equal(t, PRStatus("CLOSED"), StatusClosed)
+		assert.Equal(t, PRStatus("REOPENED"), StatusReopened)
+	})
+
+	t.Run("PRStatus IsOpen", func(t *testing.T) {
+		assert.True(t, StatusOpen.IsOpen())
+		assert.True(t, StatusReopened.IsOpen())
+		assert.False(t, StatusDraft.IsOpen())
+		assert.False(t, StatusClosed.IsOpen())
+		assert.False(t, StatusMerged.IsOpen())
+	})
+
+	t.Run("ReviewerStrategy constants", func(t *testing.T) {
+		assert.Equal(t, ReviewerStrategy("random"), StrategyRandom)
+		assert.Equal(t, ReviewerStrategy("round_robin"), StrategyRoundRobin)
+		assert.Equal(t, ReviewerStrategy("least_loaded"), StrategyLeastLoaded)
+		assert.Equal(t, ReviewerStrategy("weighted"), StrategyWeighted)
+		assert.Equal(t, ReviewerStrategy("expertise"), StrategyExpertise)
 	})
 }
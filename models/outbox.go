@@ -0,0 +1,38 @@
+package models
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// EventEnvelope — доменное событие в том виде, в каком оно публикуется наружу:
+// запись журнала, команда и состояние PR на момент события (для событий
+// пользователей PullRequest не заполняется). Это же тело получают вебхуки.
+type EventEnvelope struct {
+	IdempotencyKey string       `json:"idempotency_key"`
+	Event          PREvent      `json:"event"`
+	TeamName       string       `json:"team_name,omitempty"`
+	PullRequest    *PullRequest `json:"pull_request,omitempty"`
+}
+
+// EventIdempotencyKey — ключ публикации события журнала. Одинаков при всех
+// повторных публикациях, по нему получатели отбрасывают дубликаты.
+func EventIdempotencyKey(eventID int64) string {
+	return "pr-event-" + strconv.FormatInt(eventID, 10)
+}
+
+// OutboxEvent — событие, записанное в outbox в транзакции изменения
+// и ожидающее публикации. Payload — EventEnvelope в JSON.
+type OutboxEvent struct {
+	ID             int64           `json:"id" db:"id"`
+	IdempotencyKey string          `json:"idempotency_key" db:"idempotency_key"`
+	EventID        int64           `json:"event_id" db:"event_id"`
+	EventType      EventType       `json:"event_type" db:"event_type"`
+	TeamName       string          `json:"team_name,omitempty" db:"team_name"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt" db:"next_attempt_at"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// SortOrder — направление сортировки списков
+type SortOrder string
+
+const (
+	SortDesc SortOrder = "desc"
+	SortAsc  SortOrder = "asc"
+)
+
+// ErrInvalidCursor — курсор страницы повреждён или выдан не этим сервисом
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PageCursor — последняя запись страницы в списке, упорядоченном по (Time, ID).
+// Следующая страница начинается строго после неё, поэтому новые записи
+// не сдвигают уже полученные страницы. SortBy и Order — сортировка списка,
+// для которой выдан курсор: с другой сортировкой он не имеет смысла.
+type PageCursor struct {
+	SortBy PRSortField
+	Order  SortOrder
+	Time   time.Time
+	ID     string
+}
+
+// Encode возвращает непрозрачное представление курсора для клиента
+func (c PageCursor) Encode() string {
+	raw := strings.Join([]string{
+		string(c.SortBy), string(c.Order), c.Time.UTC().Format(time.RFC3339Nano), c.ID,
+	}, "|")
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Matches сообщает, выдан ли курсор для сортировки sortBy и order
+func (c PageCursor) Matches(sortBy PRSortField, order SortOrder) bool {
+	return c.SortBy == sortBy && c.Order == order
+}
+
+// DecodePageCursor разбирает курсор, полученный из Encode
+func DecodePageCursor(value string) (*PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	// ID идёт последним и может сам содержать разделитель
+	parts := strings.SplitN(string(raw), "|", 4)
+	if len(parts) != 4 || parts[0] == "" || parts[1] == "" || parts[3] == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, parts[2])
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &PageCursor{SortBy: PRSortField(parts[0]), Order: SortOrder(parts[1]), Time: t, ID: parts[3]}, nil
+}
+
+// After сообщает, идёт ли запись (t, id) после курсора при сортировке order
+func (c PageCursor) After(t time.Time, id string, order SortOrder) bool {
+	if order == SortAsc {
+		return t.After(c.Time) || (t.Equal(c.Time) && id > c.ID)
+	}
+	return t.Before(c.Time) || (t.Equal(c.Time) && id < c.ID)
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPageCursor(t *testing.T) {
+	at := time.Date(2025, 10, 1, 12, 30, 0, 123456000, time.UTC)
+	cursor := PageCursor{SortBy: SortByMergedAt, Order: SortAsc, Time: at, ID: "pr|1"}
+
+	decoded, err := DecodePageCursor(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, at.Equal(decoded.Time))
+	assert.Equal(t, "pr|1", decoded.ID)
+	assert.True(t, decoded.Matches(SortByMergedAt, SortAsc))
+	assert.False(t, decoded.Matches(SortByMergedAt, SortDesc))
+	assert.False(t, decoded.Matches(SortByCreatedAt, SortAsc))
+
+	// Курсор прежнего формата без сортировки не принимается
+	legacy := base64.RawURLEncoding.EncodeToString([]byte(at.Format(time.RFC3339Nano) + "|pr-1"))
+
+	for _, value := range []string{"", "not base64!", "bm8tc2VwYXJhdG9y", "MjAyNS0xMC0wMXw", legacy} {
+		_, err := DecodePageCursor(value)
+		assert.ErrorIs(t, err, ErrInvalidCursor, value)
+	}
+}
+
+func TestPageCursorAfter(t *testing.T) {
+	at := time.Date(2025, 10, 1, 12, 0, 0, 0, time.UTC)
+	cursor := PageCursor{Time: at, ID: "pr-2"}
+
+	assert.True(t, cursor.After(at.Add(-time.Second), "pr-9", SortDesc))
+	assert.True(t, cursor.After(at, "pr-1", SortDesc))
+	assert.False(t, cursor.After(at, "pr-2", SortDesc))
+	assert.False(t, cursor.After(at.Add(time.Second), "pr-1", SortDesc))
+
+	assert.True(t, cursor.After(at.Add(time.Second), "pr-1", SortAsc))
+	assert.True(t, cursor.After(at, "pr-3", SortAsc))
+	assert.False(t, cursor.After(at, "pr-2", SortAsc))
+	assert.False(t, cursor.After(at.Add(-time.Second), "pr-9", SortAsc))
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+// StatsFilter ограничивает статистику временным окном [From, To) и командой.
+// Пустые поля не ограничивают выборку.
+type StatsFilter struct {
+	From     *time.Time
+	To       *time.Time
+	TeamName string
+}
+
+// UserStats — нагрузка ревьюера: назначения в окне и открытые из них
+type UserStats struct {
+	UserID           string `json:"user_id"`
+	Username         string `json:"username"`
+	TeamName         string `json:"team_name"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+}
+
+// TeamStats — PR, созданные участниками команды, и назначения на её участников
+type TeamStats struct {
+	TeamName         string `json:"team_name"`
+	PRsTotal         int    `json:"prs_total"`
+	PRsOpen          int    `json:"prs_open"`
+	PRsMerged        int    `json:"prs_merged"`
+	OpenAssignments  int    `json:"open_assignments"`
+	TotalAssignments int    `json:"total_assignments"`
+}
+
+// MergeTimeStats — среднее время от создания PR до мерджа
+type MergeTimeStats struct {
+	TeamName        string  `json:"team_name,omitempty"`
+	MergedPRs       int     `json:"merged_prs"`
+	AvgMergeSeconds float64 `json:"avg_merge_seconds"`
+}
+
+// ReassignmentStats — сколько раз пользователя сняли с ревью и назначили взамен другого
+type ReassignmentStats struct {
+	UserID         string `json:"user_id"`
+	TeamName       string `json:"team_name,omitempty"`
+	ReassignedFrom int    `json:"reassigned_from"`
+	ReassignedTo   int    `json:"reassigned_to"`
+}
+
+// MergeTimeReport — среднее время до мерджа в целом и по командам
+type MergeTimeReport struct {
+	Overall MergeTimeStats   `json:"overall"`
+	Teams   []MergeTimeStats `json:"teams"`
+}
+
+// ReassignmentReport — переназначения по пользователям и их общее число
+type ReassignmentReport struct {
+	TotalReassignments int                 `json:"total_reassignments"`
+	Users              []ReassignmentStats `json:"users"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventTypes — события, о которых сообщают исходящие вебхуки
+var WebhookEventTypes = []EventType{
+	EventPRCreated,
+	EventReviewerAssigned,
+	EventReviewerReassigned,
+	EventPRMerged,
+	EventUserDeactivated,
+}
+
+func IsWebhookEvent(eventType EventType) bool {
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscription — подписка внешнего сервиса на события.
+// Пустой TeamName — события всех команд, пустой EventTypes — все WebhookEventTypes.
+// Secret возвращается клиенту только при создании подписки.
+type WebhookSubscription struct {
+	ID         int64       `json:"subscription_id" db:"id"`
+	TeamName   string      `json:"team_name,omitempty" db:"team_name"`
+	URL        string      `json:"url" db:"url"`
+	Secret     string      `json:"secret,omitempty" db:"secret"`
+	EventTypes []EventType `json:"event_types" db:"event_types"`
+	CreatedAt  time.Time   `json:"createdAt" db:"created_at"`
+}
+
+// Matches сообщает, нужно ли отправить подписчику событие eventType команды teamName
+func (s *WebhookSubscription) Matches(teamName string, eventType EventType) bool {
+	if s.TeamName != "" && s.TeamName != teamName {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery — доставка события одному подписчику.
+// URL и Secret подписки заполняются при выборке доставок к отправке.
+type WebhookDelivery struct {
+	ID             int64           `json:"delivery_id" db:"id"`
+	SubscriptionID int64           `json:"subscription_id" db:"subscription_id"`
+	EventID        int64           `json:"event_id" db:"event_id"`
+	EventType      EventType       `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	NextAttemptAt  time.Time       `json:"nextAttemptAt" db:"next_attempt_at"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+
+	URL    string `json:"-" db:"-"`
+	Secret string `json:"-" db:"-"`
+}
+
+// WebhookDeadLetter — доставка, исчерпавшая все попытки
+type WebhookDeadLetter struct {
+	ID             int64           `json:"dead_letter_id" db:"id"`
+	SubscriptionID int64           `json:"subscription_id" db:"subscription_id"`
+	EventID        int64           `json:"event_id" db:"event_id"`
+	EventType      EventType       `json:"event_type" db:"event_type"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	LastError      string          `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"createdAt" db:"created_at"`
+	FailedAt       time.Time       `json:"failedAt" db:"failed_at"`
+}
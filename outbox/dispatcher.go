@@ -0,0 +1,125 @@
+// Package outbox публикует события, записанные в outbox_events в транзакциях
+// изменений, в подключаемые приёмники.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"reviewtask/models"
+	"reviewtask/repo"
+)
+
+// Config — параметры публикации
+type Config struct {
+	// PollInterval — пауза между опросами пустого outbox
+	PollInterval time.Duration
+	// BatchSize — сколько событий забирается за раз
+	BatchSize int
+	// BaseBackoff и MaxBackoff — задержка перед повтором: BaseBackoff * 2^(n-1), не больше MaxBackoff.
+	// Попытки не ограничены: событие из outbox не теряется.
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Lease — на сколько событие скрывается от других диспетчеров на время публикации
+	Lease time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		BatchSize:    50,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+		Lease:        time.Minute,
+	}
+}
+
+// Backoff — задержка перед следующей попыткой после attempt неудачных
+func (c Config) Backoff(attempt int) time.Duration {
+	delay := c.BaseBackoff
+	for i := 1; i < attempt && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+	return delay
+}
+
+// Sink — приёмник событий. Если хотя бы один приёмник вернул ошибку, событие
+// позже публикуется повторно во все приёмники, поэтому Publish должен выдерживать
+// дубликаты; отличить их можно по IdempotencyKey.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// Dispatcher разбирает outbox: событие удаляется, только когда все приёмники
+// приняли его (доставка «хотя бы один раз»)
+type Dispatcher struct {
+	queue repo.OutboxQueue
+	sinks []Sink
+	cfg   Config
+	now   func() time.Time
+}
+
+func NewDispatcher(queue repo.OutboxQueue, cfg Config, sinks ...Sink) *Dispatcher {
+	return &Dispatcher{queue: queue, sinks: sinks, cfg: cfg, now: time.Now}
+}
+
+// Run публикует события до отмены ctx
+func (d *Dispatcher) Run(ctx context.Context) {
+	for {
+		n, err := d.ProcessBatch(ctx)
+		if err != nil {
+			log.Printf("outbox dispatcher: %v", err)
+		}
+
+		if err == nil && n == d.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.cfg.PollInterval):
+		}
+	}
+}
+
+// ProcessBatch публикует одну пачку событий в порядке записи и возвращает их число
+func (d *Dispatcher) ProcessBatch(ctx context.Context) (int, error) {
+	events, err := d.queue.ClaimOutboxEvents(d.now(), d.cfg.BatchSize, d.cfg.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("claim outbox events: %w", err)
+	}
+
+	for _, event := range events {
+		if err := d.handle(ctx, event); err != nil {
+			return len(events), err
+		}
+	}
+	return len(events), nil
+}
+
+func (d *Dispatcher) handle(ctx context.Context, event models.OutboxEvent) error {
+	publishErr := d.publish(ctx, event)
+	if publishErr == nil {
+		return d.queue.CompleteOutboxEvent(event.ID)
+	}
+
+	attempt := event.Attempts + 1
+	log.Printf("outbox event %s (attempt %d): %v", event.IdempotencyKey, attempt, publishErr)
+	return d.queue.RetryOutboxEvent(event.ID, d.now().Add(d.cfg.Backoff(attempt)), publishErr.Error())
+}
+
+func (d *Dispatcher) publish(ctx context.Context, event models.OutboxEvent) error {
+	for _, sink := range d.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			return fmt.Errorf("%s: %w", sink.Name(), err)
+		}
+	}
+	return nil
+}
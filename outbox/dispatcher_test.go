@@ -0,0 +1,145 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"reviewtask/models"
+	"reviewtask/repo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink запоминает ключи опубликованных событий; failures — сколько
+// первых вызовов завершатся ошибкой
+type recordingSink struct {
+	name      string
+	failures  int
+	published []string
+}
+
+func (s *recordingSink) Name() string { return s.name }
+
+func (s *recordingSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("sink unavailable")
+	}
+	s.published = append(s.published, event.IdempotencyKey)
+	return nil
+}
+
+func addEvents(t *testing.T, store *repo.MemoryStore, ids ...int64) {
+	t.Helper()
+	for _, id := range ids {
+		require.NoError(t, store.AddOutboxEvent(&models.OutboxEvent{
+			IdempotencyKey: models.EventIdempotencyKey(id),
+			EventID:        id,
+			EventType:      models.EventPRCreated,
+			Payload:        []byte(`{"idempotency_key":"` + models.EventIdempotencyKey(id) + `"}`),
+		}))
+	}
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.BaseBackoff = time.Minute
+	cfg.MaxBackoff = time.Hour
+	return cfg
+}
+
+func TestDispatcherPublishesInOrder(t *testing.T) {
+	store := repo.NewMemoryStore()
+	addEvents(t, store, 1, 2, 3)
+
+	first, second := &recordingSink{name: "first"}, &recordingSink{name: "second"}
+	d := NewDispatcher(store, testConfig(), first, second)
+
+	n, err := d.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+
+	keys := []string{"pr-event-1", "pr-event-2", "pr-event-3"}
+	assert.Equal(t, keys, first.published)
+	assert.Equal(t, keys, second.published)
+
+	n, err = d.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "published events leave the outbox")
+}
+
+func TestDispatcherRetriesUntilAllSinksAccept(t *testing.T) {
+	store := repo.NewMemoryStore()
+	addEvents(t, store, 1)
+
+	healthy, flaky := &recordingSink{name: "healthy"}, &recordingSink{name: "flaky", failures: 2}
+	now := time.Now()
+	d := NewDispatcher(store, testConfig(), healthy, flaky)
+	d.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	_, err := d.ProcessBatch(ctx)
+	require.NoError(t, err)
+
+	// Повтор — после задержки, которая растёт с каждой попыткой
+	now = now.Add(time.Minute)
+	_, err = d.ProcessBatch(ctx)
+	require.NoError(t, err)
+
+	now = now.Add(time.Minute)
+	n, err := d.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	now = now.Add(time.Minute)
+	_, err = d.ProcessBatch(ctx)
+	require.NoError(t, err)
+
+	// Хотя бы один раз: исправный приёмник получил дубликаты с тем же ключом
+	assert.Equal(t, []string{"pr-event-1", "pr-event-1", "pr-event-1"}, healthy.published)
+	assert.Equal(t, []string{"pr-event-1"}, flaky.published)
+
+	now = now.Add(time.Hour)
+	n, err = d.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+}
+
+func TestFileSinkAppendsJSONLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"existing\":true}\n"), 0o644))
+
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+	defer sink.Close()
+
+	store := repo.NewMemoryStore()
+	addEvents(t, store, 1, 2)
+	_, err = NewDispatcher(store, testConfig(), sink).ProcessBatch(context.Background())
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Equal(t, []string{
+		`{"existing":true}`,
+		`{"idempotency_key":"pr-event-1"}`,
+		`{"idempotency_key":"pr-event-2"}`,
+	}, lines)
+}
+
+func TestWriterSink(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink("buffer", &buf)
+
+	err := sink.Publish(context.Background(), models.OutboxEvent{Payload: []byte(`{"a":1}`)})
+	require.NoError(t, err)
+	assert.Equal(t, "{\"a\":1}\n", buf.String())
+}
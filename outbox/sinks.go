@@ -0,0 +1,62 @@
+package outbox
+
+import (
+	"context"
+	"io"
+	"os"
+	"sync"
+
+	"reviewtask/models"
+)
+
+// WriterSink пишет события строками JSON (EventEnvelope) в поток или файл
+type WriterSink struct {
+	name string
+	mu   sync.Mutex
+	w    io.Writer
+}
+
+func NewWriterSink(name string, w io.Writer) *WriterSink {
+	return &WriterSink{name: name, w: w}
+}
+
+func NewStdoutSink() *WriterSink {
+	return NewWriterSink("stdout", os.Stdout)
+}
+
+// NewFileSink дописывает события в файл path; каждая запись сбрасывается на диск
+// до подтверждения, чтобы после сбоя событие не пропало
+func NewFileSink(path string) (*WriterSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return NewWriterSink("file:"+path, f), nil
+}
+
+func (s *WriterSink) Name() string {
+	return s.name
+}
+
+func (s *WriterSink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	line := append(append([]byte(nil), event.Payload...), '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.w.Write(line); err != nil {
+		return err
+	}
+	if f, ok := s.w.(*os.File); ok && f != os.Stdout {
+		return f.Sync()
+	}
+	return nil
+}
+
+// Close закрывает файл приёмника
+func (s *WriterSink) Close() error {
+	if c, ok := s.w.(io.Closer); ok && s.w != os.Stdout {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"database/sql"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// availableUserCondition исключает пользователей, отсутствующих в данный момент.
+// Используется в выборках кандидатов в ревьюеры по таблице users.
+const availableUserCondition = `NOT EXISTS (
+      SELECT 1 FROM user_absences a
+      WHERE a.user_id = users.user_id AND a.cancelled_at IS NULL
+        AND a.starts_at <= NOW() AND a.ends_at > NOW()
+    )`
+
+func (r *Repository) CreateAbsence(absence *models.Absence) error {
+	return r.q.QueryRow(
+		`INSERT INTO user_absences (user_id, starts_at, ends_at, reason)
+     VALUES ($1, $2, $3, $4)
+     RETURNING id, created_at`,
+		absence.UserID, absence.StartsAt, absence.EndsAt, nullString(absence.Reason),
+	).Scan(&absence.ID, &absence.CreatedAt)
+}
+
+func (r *Repository) GetAbsence(absenceID int64) (*models.Absence, error) {
+	row := r.q.QueryRow(
+		`SELECT id, user_id, starts_at, ends_at, reason, created_at, cancelled_at
+     FROM user_absences WHERE id = $1`,
+		absenceID,
+	)
+
+	absence, err := scanAbsence(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.ErrAbsenceNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &absence, nil
+}
+
+// GetUserAbsences возвращает отсутствия пользователя; без includePast —
+// только неотменённые, которые ещё не закончились
+func (r *Repository) GetUserAbsences(userID string, includePast bool) ([]models.Absence, error) {
+	rows, err := r.q.Query(
+		`SELECT id, user_id, starts_at, ends_at, reason, created_at, cancelled_at
+     FROM user_absences
+     WHERE user_id = $1 AND ($2 OR (cancelled_at IS NULL AND ends_at > NOW()))
+     ORDER BY starts_at, id`,
+		userID, includePast,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	absences := []models.Absence{}
+	for rows.Next() {
+		absence, err := scanAbsence(rows)
+		if err != nil {
+			return nil, err
+		}
+		absences = append(absences, absence)
+	}
+
+	return absences, rows.Err()
+}
+
+func (r *Repository) CancelAbsence(absenceID int64) error {
+	_, err := r.q.Exec(
+		"UPDATE user_absences SET cancelled_at = NOW() WHERE id = $1 AND cancelled_at IS NULL",
+		absenceID,
+	)
+	return err
+}
+
+// rowScanner — общий метод Scan у *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAbsence(rows rowScanner) (models.Absence, error) {
+	var absence models.Absence
+	var reason sql.NullString
+	var cancelledAt sql.NullTime
+	err := rows.Scan(
+		&absence.ID,
+		&absence.UserID,
+		&absence.StartsAt,
+		&absence.EndsAt,
+		&reason,
+		&absence.CreatedAt,
+		&cancelledAt,
+	)
+	if err != nil {
+		return absence, err
+	}
+
+	absence.Reason = reason.String
+	if cancelledAt.Valid {
+		absence.CancelledAt = &cancelledAt.Time
+	}
+	return absence, nil
+}
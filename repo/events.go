@@ -0,0 +1,73 @@
+package repo
+
+import (
+	"database/sql"
+	"reviewtask/models"
+)
+
+// AddEvent добавляет запись в журнал событий. Чтобы событие фиксировалось
+// вместе с изменением, вызывайте его на репозитории из WithTx.
+func (r *Repository) AddEvent(event *models.PREvent) error {
+	return r.q.QueryRow(
+		`INSERT INTO pr_events (pull_request_id, event_type, actor_id, user_id, previous_user_id, reason)
+     VALUES ($1, $2, $3, $4, $5, $6)
+     RETURNING id, created_at`,
+		nullString(event.PullRequestID),
+		event.EventType,
+		nullString(event.ActorID),
+		nullString(event.UserID),
+		nullString(event.PreviousUserID),
+		nullString(event.Reason),
+	).Scan(&event.ID, &event.CreatedAt)
+}
+
+// GetPREvents возвращает историю PR в порядке возникновения событий
+func (r *Repository) GetPREvents(pullRequestID string) ([]models.PREvent, error) {
+	rows, err := r.q.Query(
+		`SELECT id, pull_request_id, event_type, actor_id, user_id, previous_user_id, reason, created_at
+     FROM pr_events
+     WHERE pull_request_id = $1
+     ORDER BY id`,
+		pullRequestID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.PREvent{}
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func scanEvent(rows *sql.Rows) (models.PREvent, error) {
+	var event models.PREvent
+	var prID, actorID, userID, previousUserID, reason sql.NullString
+	err := rows.Scan(
+		&event.ID,
+		&prID,
+		&event.EventType,
+		&actorID,
+		&userID,
+		&previousUserID,
+		&reason,
+		&event.CreatedAt,
+	)
+	event.PullRequestID = prID.String
+	event.ActorID = actorID.String
+	event.UserID = userID.String
+	event.PreviousUserID = previousUserID.String
+	event.Reason = reason.String
+	return event, err
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,76 @@
+package repo
+
+import (
+	"database/sql"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+func (r *Repository) AddUserIdentity(identity *models.UserIdentity) error {
+	err := r.q.QueryRow(
+		`INSERT INTO user_identities (provider, external_id, user_id)
+     VALUES ($1, $2, $3)
+     ON CONFLICT (provider, external_id) DO NOTHING
+     RETURNING created_at`,
+		identity.Provider, identity.ExternalID, identity.UserID,
+	).Scan(&identity.CreatedAt)
+	if err == sql.ErrNoRows {
+		return apperr.ErrIdentityTaken
+	}
+	return err
+}
+
+func (r *Repository) GetUserIdentity(provider, externalID string) (*models.UserIdentity, error) {
+	var identity models.UserIdentity
+	err := r.q.QueryRow(
+		`SELECT provider, external_id, user_id, created_at
+     FROM user_identities WHERE provider = $1 AND external_id = $2`,
+		provider, externalID,
+	).Scan(&identity.Provider, &identity.ExternalID, &identity.UserID, &identity.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.ErrIdentityNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &identity, nil
+}
+
+func (r *Repository) ListUserIdentities(userID string) ([]models.UserIdentity, error) {
+	rows, err := r.q.Query(
+		`SELECT provider, external_id, user_id, created_at
+     FROM user_identities WHERE user_id = $1
+     ORDER BY provider, external_id`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	identities := []models.UserIdentity{}
+	for rows.Next() {
+		var identity models.UserIdentity
+		if err := rows.Scan(&identity.Provider, &identity.ExternalID, &identity.UserID, &identity.CreatedAt); err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+func (r *Repository) DeleteUserIdentity(provider, externalID string) error {
+	result, err := r.q.Exec(
+		"DELETE FROM user_identities WHERE provider = $1 AND external_id = $2",
+		provider, externalID,
+	)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return apperr.ErrIdentityNotFound
+	}
+	return err
+}
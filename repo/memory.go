@@ -0,0 +1,1344 @@
+package repo
+
+import (
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore — реализация Store в памяти для тестов сервиса без PostgreSQL.
+// Транзакции выполняются по одной: WithTx удерживает блокировку хранилища
+// (как SELECT ... FOR UPDATE на всех строках) и при ошибке восстанавливает
+// снимок состояния. Ограничения внешних ключей и уникальности проверяются
+// так же, как в схеме БД.
+type MemoryStore struct {
+	mu    *sync.Mutex
+	state *memoryState
+
+	// inTx и txNow — признак транзакции и время её начала (аналог NOW() в PostgreSQL)
+	inTx  bool
+	txNow time.Time
+}
+
+type memoryState struct {
+	teams     map[string]*memoryTeam
+	users     map[string]models.User
+	prs       map[string]*memoryPR
+	reviewers []*memoryReviewer
+	events    []models.PREvent
+	absences  []models.Absence
+
+	// identities — по ключу identityKey(provider, externalID)
+	identities map[string]models.UserIdentity
+
+	outbox             []models.OutboxEvent
+	webhookSubs        []models.WebhookSubscription
+	webhookDeliveries  []models.WebhookDelivery
+	webhookDeadLetters []models.WebhookDeadLetter
+
+	lastEventID      int64
+	lastAbsenceID    int64
+	lastOutboxID     int64
+	lastWebhookSubID int64
+	lastDeliveryID   int64
+	lastDeadLetterID int64
+}
+
+type memoryTeam struct {
+	strategy models.ReviewerStrategy
+	params   models.StrategyParams
+	partners []string
+	settings *models.TeamSettings
+}
+
+type memoryPR struct {
+	id        string
+	name      string
+	authorID  string
+	status    models.PRStatus
+	createdAt time.Time
+	mergedAt  *time.Time
+	closedAt  *time.Time
+}
+
+type memoryReviewer struct {
+	prID       string
+	userID     string
+	state      string
+	fallback   bool
+	assignedAt time.Time
+	verdict    models.ReviewVerdict
+	comment    string
+	verdictAt  time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		mu: &sync.Mutex{},
+		state: &memoryState{
+			teams:      make(map[string]*memoryTeam),
+			users:      make(map[string]models.User),
+			prs:        make(map[string]*memoryPR),
+			identities: make(map[string]models.UserIdentity),
+		},
+	}
+}
+
+func (m *MemoryStore) WithTx(fn func(tx Store) error) error {
+	if m.inTx {
+		return fn(m)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := m.state.clone()
+	tx := &MemoryStore{mu: m.mu, state: m.state, inTx: true, txNow: time.Now()}
+	if err := fn(tx); err != nil {
+		*m.state = *snapshot
+		return err
+	}
+	return nil
+}
+
+// lock захватывает хранилище вне транзакции; внутри WithTx блокировка уже удерживается
+func (m *MemoryStore) lock() func() {
+	if m.inTx {
+		return func() {}
+	}
+	m.mu.Lock()
+	return m.mu.Unlock
+}
+
+func (m *MemoryStore) now() time.Time {
+	if m.inTx {
+		return m.txNow
+	}
+	return time.Now()
+}
+
+func (s *memoryState) clone() *memoryState {
+	c := &memoryState{
+		teams:         make(map[string]*memoryTeam, len(s.teams)),
+		users:         make(map[string]models.User, len(s.users)),
+		prs:           make(map[string]*memoryPR, len(s.prs)),
+		reviewers:     make([]*memoryReviewer, len(s.reviewers)),
+		events:        append([]models.PREvent(nil), s.events...),
+		absences:      append([]models.Absence(nil), s.absences...),
+		lastEventID:   s.lastEventID,
+		lastAbsenceID: s.lastAbsenceID,
+		identities:    make(map[string]models.UserIdentity, len(s.identities)),
+
+		outbox:             append([]models.OutboxEvent(nil), s.outbox...),
+		lastOutboxID:       s.lastOutboxID,
+		webhookSubs:        append([]models.WebhookSubscription(nil), s.webhookSubs...),
+		webhookDeliveries:  append([]models.WebhookDelivery(nil), s.webhookDeliveries...),
+		webhookDeadLetters: append([]models.WebhookDeadLetter(nil), s.webhookDeadLetters...),
+		lastWebhookSubID:   s.lastWebhookSubID,
+		lastDeliveryID:     s.lastDeliveryID,
+		lastDeadLetterID:   s.lastDeadLetterID,
+	}
+	for key, identity := range s.identities {
+		c.identities[key] = identity
+	}
+	for name, team := range s.teams {
+		t := *team
+		t.partners = append([]string(nil), team.partners...)
+		c.teams[name] = &t
+	}
+	for id, user := range s.users {
+		c.users[id] = user
+	}
+	for id, pr := range s.prs {
+		p := *pr
+		c.prs[id] = &p
+	}
+	for i, rv := range s.reviewers {
+		r := *rv
+		c.reviewers[i] = &r
+	}
+	return c
+}
+
+// Team methods
+func (m *MemoryStore) CreateTeam(team *models.Team) error {
+	defer m.lock()()
+
+	if _, ok := m.state.teams[team.TeamName]; ok {
+		return fmt.Errorf("team %q already exists", team.TeamName)
+	}
+	for _, member := range team.Members {
+		if _, ok := m.state.users[member.UserID]; ok {
+			return fmt.Errorf("user %q already exists", member.UserID)
+		}
+	}
+	if err := m.checkPartners(team.TeamName, team.PartnerTeams); err != nil {
+		return err
+	}
+
+	if team.ReviewerStrategy == "" {
+		team.ReviewerStrategy = models.StrategyRandom
+	}
+
+	m.state.teams[team.TeamName] = &memoryTeam{
+		strategy: team.ReviewerStrategy,
+		params:   copyStrategyParams(team.StrategyParams),
+		partners: append([]string(nil), team.PartnerTeams...),
+	}
+	for _, member := range team.Members {
+		m.state.users[member.UserID] = models.User{
+			UserID:   member.UserID,
+			Username: member.Username,
+			TeamName: team.TeamName,
+			IsActive: member.IsActive,
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetTeam(teamName string) (*models.Team, error) {
+	defer m.lock()()
+
+	stored, ok := m.state.teams[teamName]
+	if !ok {
+		return nil, apperr.ErrTeamNotFound
+	}
+
+	team := &models.Team{
+		TeamName:         teamName,
+		ReviewerStrategy: stored.strategy,
+		StrategyParams:   copyStrategyParams(stored.params),
+		PartnerTeams:     append([]string(nil), stored.partners...),
+	}
+	for _, user := range m.sortedUsers() {
+		if user.TeamName == teamName {
+			team.Members = append(team.Members, models.TeamMember{
+				UserID:   user.UserID,
+				Username: user.Username,
+				IsActive: user.IsActive,
+			})
+		}
+	}
+	return team, nil
+}
+
+func (m *MemoryStore) SetTeamPartners(teamName string, partnerTeams []string) error {
+	defer m.lock()()
+
+	team, ok := m.state.teams[teamName]
+	if !ok {
+		return fmt.Errorf("team %q does not exist", teamName)
+	}
+	if err := m.checkPartners(teamName, partnerTeams); err != nil {
+		return err
+	}
+
+	team.partners = append([]string(nil), partnerTeams...)
+	return nil
+}
+
+func (m *MemoryStore) checkPartners(teamName string, partnerTeams []string) error {
+	for _, partner := range partnerTeams {
+		if _, ok := m.state.teams[partner]; !ok || partner == teamName {
+			return fmt.Errorf("invalid partner team %q", partner)
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) UpdateTeamStrategy(teamName string, strategy models.ReviewerStrategy, params models.StrategyParams) error {
+	defer m.lock()()
+
+	team, ok := m.state.teams[teamName]
+	if !ok {
+		return apperr.ErrTeamNotFound
+	}
+
+	team.strategy = strategy
+	team.params = copyStrategyParams(params)
+	return nil
+}
+
+func (m *MemoryStore) GetTeamSettings(teamName string) (*models.TeamSettings, error) {
+	defer m.lock()()
+
+	team, ok := m.state.teams[teamName]
+	if !ok {
+		return nil, apperr.ErrTeamNotFound
+	}
+	if team.settings == nil {
+		return models.DefaultTeamSettings(teamName), nil
+	}
+
+	settings := *team.settings
+	return &settings, nil
+}
+
+func (m *MemoryStore) UpsertTeamSettings(settings *models.TeamSettings) error {
+	defer m.lock()()
+
+	team, ok := m.state.teams[settings.TeamName]
+	if !ok {
+		return fmt.Errorf("team %q does not exist", settings.TeamName)
+	}
+
+	stored := *settings
+	team.settings = &stored
+	return nil
+}
+
+// User methods
+func (m *MemoryStore) GetUser(userID string) (*models.User, error) {
+	defer m.lock()()
+
+	user, ok := m.state.users[userID]
+	if !ok {
+		return nil, apperr.ErrUserNotFound
+	}
+	return &user, nil
+}
+
+func (m *MemoryStore) CreateUser(user *models.User) error {
+	defer m.lock()()
+
+	if _, ok := m.state.users[user.UserID]; ok {
+		return fmt.Errorf("user %q already exists", user.UserID)
+	}
+	if err := m.checkUserTeam(user.TeamName); err != nil {
+		return err
+	}
+
+	m.state.users[user.UserID] = *user
+	return nil
+}
+
+func (m *MemoryStore) UpdateUser(user *models.User) error {
+	defer m.lock()()
+
+	if _, ok := m.state.users[user.UserID]; !ok {
+		return nil
+	}
+	if err := m.checkUserTeam(user.TeamName); err != nil {
+		return err
+	}
+
+	m.state.users[user.UserID] = *user
+	return nil
+}
+
+func (m *MemoryStore) checkUserTeam(teamName string) error {
+	if teamName == "" {
+		return nil
+	}
+	if _, ok := m.state.teams[teamName]; !ok {
+		return fmt.Errorf("team %q does not exist", teamName)
+	}
+	return nil
+}
+
+func (m *MemoryStore) SetUserActive(userID string, isActive bool) error {
+	defer m.lock()()
+
+	if user, ok := m.state.users[userID]; ok {
+		user.IsActive = isActive
+		m.state.users[userID] = user
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetActiveUsersByTeam(teamName string, excludeUserIDs []string) ([]models.User, error) {
+	defer m.lock()()
+
+	return m.availableUsers(func(user models.User) bool {
+		return user.TeamName == teamName && !containsString(excludeUserIDs, user.UserID)
+	}), nil
+}
+
+func (m *MemoryStore) GetActiveUsersOutsideTeam(teamName string, excludeUserIDs []string) ([]models.User, error) {
+	defer m.lock()()
+
+	return m.availableUsers(func(user models.User) bool {
+		return user.TeamName != "" && user.TeamName != teamName && !containsString(excludeUserIDs, user.UserID)
+	}), nil
+}
+
+// availableUsers возвращает активных и не отсутствующих сейчас пользователей,
+// подходящих под match, в порядке user_id
+func (m *MemoryStore) availableUsers(match func(user models.User) bool) []models.User {
+	now := m.now()
+
+	var users []models.User
+	for _, user := range m.sortedUsers() {
+		if user.IsActive && match(user) && !m.isAbsent(user.UserID, now) {
+			users = append(users, user)
+		}
+	}
+	return users
+}
+
+func (m *MemoryStore) isAbsent(userID string, at time.Time) bool {
+	for _, absence := range m.state.absences {
+		if absence.UserID == userID && absence.CancelledAt == nil &&
+			!absence.StartsAt.After(at) && absence.EndsAt.After(at) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MemoryStore) sortedUsers() []models.User {
+	users := make([]models.User, 0, len(m.state.users))
+	for _, user := range m.state.users {
+		users = append(users, user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+	return users
+}
+
+// PR methods
+func (m *MemoryStore) CreatePR(pr *models.PullRequest) error {
+	defer m.lock()()
+
+	if _, ok := m.state.prs[pr.PullRequestID]; ok {
+		return apperr.ErrPRExists
+	}
+	if _, ok := m.state.users[pr.AuthorID]; !ok {
+		return fmt.Errorf("author %q does not exist", pr.AuthorID)
+	}
+
+	pr.CreatedAt = m.now()
+	m.state.prs[pr.PullRequestID] = &memoryPR{
+		id:        pr.PullRequestID,
+		name:      pr.PullRequestName,
+		authorID:  pr.AuthorID,
+		status:    pr.Status,
+		createdAt: pr.CreatedAt,
+	}
+
+	for _, reviewerID := range pr.AssignedReviewers {
+		if err := m.assignReviewer(pr.PullRequestID, reviewerID, containsString(pr.FallbackReviewers, reviewerID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) GetPR(pullRequestID string) (*models.PullRequest, error) {
+	defer m.lock()()
+
+	stored, ok := m.state.prs[pullRequestID]
+	if !ok {
+		return nil, apperr.ErrPRNotFound
+	}
+
+	pr := &models.PullRequest{
+		PullRequestID:     stored.id,
+		PullRequestName:   stored.name,
+		AuthorID:          stored.authorID,
+		Status:            stored.status,
+		AssignedReviewers: []string{},
+		CreatedAt:         stored.createdAt,
+		MergedAt:          copyTime(stored.mergedAt),
+		ClosedAt:          copyTime(stored.closedAt),
+	}
+
+	var assigned []*memoryReviewer
+	for _, rv := range m.state.reviewers {
+		if rv.prID == pullRequestID && rv.state == reviewerAssigned {
+			assigned = append(assigned, rv)
+		}
+	}
+	sort.Slice(assigned, func(i, j int) bool {
+		if !assigned[i].assignedAt.Equal(assigned[j].assignedAt) {
+			return assigned[i].assignedAt.Before(assigned[j].assignedAt)
+		}
+		return assigned[i].userID < assigned[j].userID
+	})
+
+	for _, rv := range assigned {
+		pr.AssignedReviewers = append(pr.AssignedReviewers, rv.userID)
+		if rv.fallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, rv.userID)
+		}
+		if rv.verdict != "" {
+			pr.Reviews = append(pr.Reviews, models.Review{
+				UserID:      rv.userID,
+				Verdict:     rv.verdict,
+				Comment:     rv.comment,
+				SubmittedAt: rv.verdictAt,
+			})
+		}
+	}
+
+	return pr, nil
+}
+
+// GetPRForUpdate совпадает с GetPR: внутри WithTx хранилище уже заблокировано целиком
+func (m *MemoryStore) GetPRForUpdate(pullRequestID string) (*models.PullRequest, error) {
+	return m.GetPR(pullRequestID)
+}
+
+func (m *MemoryStore) MergePR(pullRequestID string) error {
+	defer m.lock()()
+
+	if pr, ok := m.state.prs[pullRequestID]; ok {
+		now := m.now()
+		pr.status = models.StatusMerged
+		pr.mergedAt = &now
+	}
+	return nil
+}
+
+func (m *MemoryStore) UpdatePRStatus(pullRequestID string, status models.PRStatus) error {
+	defer m.lock()()
+
+	if pr, ok := m.state.prs[pullRequestID]; ok {
+		pr.status = status
+		pr.closedAt = nil
+		if status == models.StatusClosed {
+			now := time.Now()
+			pr.closedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) UpdatePRReviewers(pr *models.PullRequest) error {
+	defer m.lock()()
+
+	for _, rv := range m.state.reviewers {
+		if rv.prID == pr.PullRequestID && rv.state == reviewerAssigned && !containsString(pr.AssignedReviewers, rv.userID) {
+			rv.state = reviewerReplaced
+		}
+	}
+
+	for _, reviewerID := range pr.AssignedReviewers {
+		if err := m.assignReviewer(pr.PullRequestID, reviewerID, containsString(pr.FallbackReviewers, reviewerID)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignReviewer повторяет INSERT ... ON CONFLICT из Repository.assignReviewer
+func (m *MemoryStore) assignReviewer(pullRequestID, userID string, fallback bool) error {
+	if _, ok := m.state.users[userID]; !ok {
+		return fmt.Errorf("reviewer %q does not exist", userID)
+	}
+
+	for _, rv := range m.state.reviewers {
+		if rv.prID != pullRequestID || rv.userID != userID {
+			continue
+		}
+		if rv.state != reviewerAssigned {
+			*rv = memoryReviewer{
+				prID:       pullRequestID,
+				userID:     userID,
+				state:      reviewerAssigned,
+				fallback:   fallback,
+				assignedAt: m.now(),
+			}
+		}
+		return nil
+	}
+
+	m.state.reviewers = append(m.state.reviewers, &memoryReviewer{
+		prID:       pullRequestID,
+		userID:     userID,
+		state:      reviewerAssigned,
+		fallback:   fallback,
+		assignedAt: m.now(),
+	})
+	return nil
+}
+
+func (m *MemoryStore) SubmitReview(pullRequestID, userID string, verdict models.ReviewVerdict, comment string) error {
+	defer m.lock()()
+
+	for _, rv := range m.state.reviewers {
+		if rv.prID == pullRequestID && rv.userID == userID && rv.state == reviewerAssigned {
+			rv.verdict = verdict
+			rv.comment = comment
+			rv.verdictAt = m.now()
+			return nil
+		}
+	}
+	return apperr.ErrNotAssigned
+}
+
+func (m *MemoryStore) ListPRs(filter models.PRFilter) (*models.PRPage, error) {
+	defer m.lock()()
+
+	var matched []models.PullRequestShort
+	for _, pr := range m.state.prs {
+		if m.matchesPRFilter(pr, filter) {
+			matched = append(matched, models.PullRequestShort{
+				PullRequestID:   pr.id,
+				PullRequestName: pr.name,
+				AuthorID:        pr.authorID,
+				Status:          pr.status,
+				CreatedAt:       pr.createdAt,
+				MergedAt:        copyTime(pr.mergedAt),
+			})
+		}
+	}
+
+	asc := filter.Order == models.SortAsc
+	sort.Slice(matched, func(i, j int) bool {
+		ki, kj := matched[i].SortKey(filter.SortBy), matched[j].SortKey(filter.SortBy)
+		if !ki.Equal(kj) {
+			return ki.Before(kj) == asc
+		}
+		return (matched[i].PullRequestID < matched[j].PullRequestID) == asc
+	})
+
+	page := &models.PRPage{PullRequests: []models.PullRequestShort{}, Total: len(matched)}
+	for _, pr := range matched {
+		if filter.Cursor == nil || filter.Cursor.After(pr.SortKey(filter.SortBy), pr.PullRequestID, filter.Order) {
+			page.PullRequests = append(page.PullRequests, pr)
+		}
+	}
+
+	trimPage(page, filter)
+	return page, nil
+}
+
+func (m *MemoryStore) matchesPRFilter(pr *memoryPR, filter models.PRFilter) bool {
+	if len(filter.Statuses) > 0 {
+		matched := false
+		for _, status := range filter.Statuses {
+			matched = matched || status == pr.status
+		}
+		if !matched {
+			return false
+		}
+	}
+	if filter.AuthorID != "" && pr.authorID != filter.AuthorID {
+		return false
+	}
+	if filter.TeamName != "" && m.state.users[pr.authorID].TeamName != filter.TeamName {
+		return false
+	}
+	if filter.ReviewerID != "" && !m.isAssigned(pr.id, filter.ReviewerID) {
+		return false
+	}
+	if filter.NameContains != "" && !strings.Contains(strings.ToLower(pr.name), strings.ToLower(filter.NameContains)) {
+		return false
+	}
+	if !inWindow(&pr.createdAt, filter.CreatedFrom, filter.CreatedTo) {
+		return false
+	}
+	if pr.mergedAt == nil && filter.SortBy == models.SortByMergedAt {
+		return false
+	}
+	return inWindow(pr.mergedAt, filter.MergedFrom, filter.MergedTo)
+}
+
+func (m *MemoryStore) isAssigned(pullRequestID, userID string) bool {
+	for _, rv := range m.state.reviewers {
+		if rv.prID == pullRequestID && rv.userID == userID && rv.state == reviewerAssigned {
+			return true
+		}
+	}
+	return false
+}
+
+// inWindow проверяет, что t попадает в [from, to); пустое t подходит только к пустому окну
+func inWindow(t, from, to *time.Time) bool {
+	if t == nil {
+		return from == nil && to == nil
+	}
+	return (from == nil || !t.Before(*from)) && (to == nil || t.Before(*to))
+}
+
+func (m *MemoryStore) GetOpenPRIDsByReviewer(userID string) ([]string, error) {
+	defer m.lock()()
+
+	var prs []*memoryPR
+	for _, rv := range m.state.reviewers {
+		if pr := m.state.prs[rv.prID]; rv.userID == userID && rv.state == reviewerAssigned && pr.status.IsOpen() {
+			prs = append(prs, pr)
+		}
+	}
+
+	sort.Slice(prs, func(i, j int) bool {
+		if !prs[i].createdAt.Equal(prs[j].createdAt) {
+			return prs[i].createdAt.Before(prs[j].createdAt)
+		}
+		return prs[i].id < prs[j].id
+	})
+
+	prIDs := []string{}
+	for _, pr := range prs {
+		prIDs = append(prIDs, pr.id)
+	}
+	return prIDs, nil
+}
+
+func (m *MemoryStore) GetOpenReviewCounts(userIDs []string) (map[string]int, error) {
+	defer m.lock()()
+
+	counts := make(map[string]int, len(userIDs))
+	for _, rv := range m.state.reviewers {
+		if rv.state == reviewerAssigned && containsString(userIDs, rv.userID) && m.state.prs[rv.prID].status.IsOpen() {
+			counts[rv.userID]++
+		}
+	}
+	return counts, nil
+}
+
+func (m *MemoryStore) GetLastAssignedAt(userIDs []string) (map[string]time.Time, error) {
+	defer m.lock()()
+
+	lastAssigned := make(map[string]time.Time, len(userIDs))
+	for _, rv := range m.state.reviewers {
+		if containsString(userIDs, rv.userID) && rv.assignedAt.After(lastAssigned[rv.userID]) {
+			lastAssigned[rv.userID] = rv.assignedAt
+		}
+	}
+	return lastAssigned, nil
+}
+
+// Event methods
+func (m *MemoryStore) AddEvent(event *models.PREvent) error {
+	defer m.lock()()
+
+	if event.PullRequestID != "" {
+		if _, ok := m.state.prs[event.PullRequestID]; !ok {
+			return fmt.Errorf("PR %q does not exist", event.PullRequestID)
+		}
+	}
+
+	m.state.lastEventID++
+	event.ID = m.state.lastEventID
+	event.CreatedAt = time.Now()
+	m.state.events = append(m.state.events, *event)
+	return nil
+}
+
+func (m *MemoryStore) GetPREvents(pullRequestID string) ([]models.PREvent, error) {
+	defer m.lock()()
+
+	events := []models.PREvent{}
+	for _, event := range m.state.events {
+		if event.PullRequestID == pullRequestID {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+// Stats methods
+func (m *MemoryStore) GetUserStats(filter models.StatsFilter) ([]models.UserStats, error) {
+	defer m.lock()()
+
+	stats := []models.UserStats{}
+	for _, user := range m.state.users {
+		if filter.TeamName != "" && user.TeamName != filter.TeamName {
+			continue
+		}
+
+		s := models.UserStats{UserID: user.UserID, Username: user.Username, TeamName: user.TeamName}
+		for _, rv := range m.state.reviewers {
+			if rv.userID != user.UserID || !inWindow(&rv.assignedAt, filter.From, filter.To) {
+				continue
+			}
+			s.TotalAssignments++
+			if rv.state == reviewerAssigned && m.state.prs[rv.prID].status.IsOpen() {
+				s.OpenAssignments++
+			}
+		}
+		stats = append(stats, s)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].TotalAssignments != stats[j].TotalAssignments {
+			return stats[i].TotalAssignments > stats[j].TotalAssignments
+		}
+		return stats[i].UserID < stats[j].UserID
+	})
+	return stats, nil
+}
+
+func (m *MemoryStore) GetTeamStats(filter models.StatsFilter) ([]models.TeamStats, error) {
+	defer m.lock()()
+
+	byTeam := make(map[string]*models.TeamStats, len(m.state.teams))
+	for name := range m.state.teams {
+		if filter.TeamName == "" || name == filter.TeamName {
+			byTeam[name] = &models.TeamStats{TeamName: name}
+		}
+	}
+
+	for _, pr := range m.state.prs {
+		s := byTeam[m.state.users[pr.authorID].TeamName]
+		if s == nil || !inWindow(&pr.createdAt, filter.From, filter.To) {
+			continue
+		}
+		s.PRsTotal++
+		if pr.status.IsOpen() {
+			s.PRsOpen++
+		}
+		if pr.status == models.StatusMerged {
+			s.PRsMerged++
+		}
+	}
+
+	for _, rv := range m.state.reviewers {
+		s := byTeam[m.state.users[rv.userID].TeamName]
+		if s == nil || !inWindow(&rv.assignedAt, filter.From, filter.To) {
+			continue
+		}
+		s.TotalAssignments++
+		if rv.state == reviewerAssigned && m.state.prs[rv.prID].status.IsOpen() {
+			s.OpenAssignments++
+		}
+	}
+
+	stats := make([]models.TeamStats, 0, len(byTeam))
+	for _, s := range byTeam {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].TeamName < stats[j].TeamName })
+	return stats, nil
+}
+
+func (m *MemoryStore) GetMergeTimeStats(filter models.StatsFilter) ([]models.MergeTimeStats, error) {
+	defer m.lock()()
+
+	byTeam := make(map[string]*models.MergeTimeStats)
+	totals := make(map[string]float64)
+	for _, pr := range m.state.prs {
+		teamName := m.state.users[pr.authorID].TeamName
+		if pr.status != models.StatusMerged || pr.mergedAt == nil || !inWindow(pr.mergedAt, filter.From, filter.To) ||
+			(filter.TeamName != "" && teamName != filter.TeamName) {
+			continue
+		}
+
+		s, ok := byTeam[teamName]
+		if !ok {
+			s = &models.MergeTimeStats{TeamName: teamName}
+			byTeam[teamName] = s
+		}
+		s.MergedPRs++
+		totals[teamName] += pr.mergedAt.Sub(pr.createdAt).Seconds()
+	}
+
+	stats := make([]models.MergeTimeStats, 0, len(byTeam))
+	for teamName, s := range byTeam {
+		s.AvgMergeSeconds = totals[teamName] / float64(s.MergedPRs)
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].TeamName < stats[j].TeamName })
+	return stats, nil
+}
+
+func (m *MemoryStore) GetReassignmentStats(filter models.StatsFilter) ([]models.ReassignmentStats, error) {
+	defer m.lock()()
+
+	byUser := make(map[string]*models.ReassignmentStats)
+	count := func(userID string, from, to int) {
+		teamName := m.state.users[userID].TeamName
+		if userID == "" || (filter.TeamName != "" && teamName != filter.TeamName) {
+			return
+		}
+		s, ok := byUser[userID]
+		if !ok {
+			s = &models.ReassignmentStats{UserID: userID, TeamName: teamName}
+			byUser[userID] = s
+		}
+		s.ReassignedFrom += from
+		s.ReassignedTo += to
+	}
+
+	for _, event := range m.state.events {
+		if event.EventType != models.EventReviewerReassigned || !inWindow(&event.CreatedAt, filter.From, filter.To) {
+			continue
+		}
+		count(event.PreviousUserID, 1, 0)
+		count(event.UserID, 0, 1)
+	}
+
+	stats := make([]models.ReassignmentStats, 0, len(byUser))
+	for _, s := range byUser {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].ReassignedFrom != stats[j].ReassignedFrom {
+			return stats[i].ReassignedFrom > stats[j].ReassignedFrom
+		}
+		return stats[i].UserID < stats[j].UserID
+	})
+	return stats, nil
+}
+
+// Absence methods
+func (m *MemoryStore) CreateAbsence(absence *models.Absence) error {
+	defer m.lock()()
+
+	if _, ok := m.state.users[absence.UserID]; !ok {
+		return fmt.Errorf("user %q does not exist", absence.UserID)
+	}
+
+	m.state.lastAbsenceID++
+	absence.ID = m.state.lastAbsenceID
+	absence.CreatedAt = m.now()
+	absence.CancelledAt = nil
+	m.state.absences = append(m.state.absences, *absence)
+	return nil
+}
+
+func (m *MemoryStore) GetAbsence(absenceID int64) (*models.Absence, error) {
+	defer m.lock()()
+
+	for _, absence := range m.state.absences {
+		if absence.ID == absenceID {
+			absence.CancelledAt = copyTime(absence.CancelledAt)
+			return &absence, nil
+		}
+	}
+	return nil, apperr.ErrAbsenceNotFound
+}
+
+func (m *MemoryStore) GetUserAbsences(userID string, includePast bool) ([]models.Absence, error) {
+	defer m.lock()()
+
+	now := m.now()
+	absences := []models.Absence{}
+	for _, absence := range m.state.absences {
+		if absence.UserID != userID {
+			continue
+		}
+		if includePast || (absence.CancelledAt == nil && absence.EndsAt.After(now)) {
+			absence.CancelledAt = copyTime(absence.CancelledAt)
+			absences = append(absences, absence)
+		}
+	}
+
+	sort.SliceStable(absences, func(i, j int) bool {
+		if !absences[i].StartsAt.Equal(absences[j].StartsAt) {
+			return absences[i].StartsAt.Before(absences[j].StartsAt)
+		}
+		return absences[i].ID < absences[j].ID
+	})
+	return absences, nil
+}
+
+func (m *MemoryStore) CancelAbsence(absenceID int64) error {
+	defer m.lock()()
+
+	for i := range m.state.absences {
+		if m.state.absences[i].ID == absenceID && m.state.absences[i].CancelledAt == nil {
+			now := time.Now()
+			m.state.absences[i].CancelledAt = &now
+		}
+	}
+	return nil
+}
+
+// Identity methods
+func identityKey(provider, externalID string) string {
+	return provider + "\x00" + externalID
+}
+
+func (m *MemoryStore) AddUserIdentity(identity *models.UserIdentity) error {
+	defer m.lock()()
+
+	if _, ok := m.state.users[identity.UserID]; !ok {
+		return fmt.Errorf("user %q does not exist", identity.UserID)
+	}
+
+	key := identityKey(identity.Provider, identity.ExternalID)
+	if _, ok := m.state.identities[key]; ok {
+		return apperr.ErrIdentityTaken
+	}
+
+	identity.CreatedAt = m.now()
+	m.state.identities[key] = *identity
+	return nil
+}
+
+func (m *MemoryStore) GetUserIdentity(provider, externalID string) (*models.UserIdentity, error) {
+	defer m.lock()()
+
+	identity, ok := m.state.identities[identityKey(provider, externalID)]
+	if !ok {
+		return nil, apperr.ErrIdentityNotFound
+	}
+	return &identity, nil
+}
+
+func (m *MemoryStore) ListUserIdentities(userID string) ([]models.UserIdentity, error) {
+	defer m.lock()()
+
+	identities := []models.UserIdentity{}
+	for _, identity := range m.state.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+
+	sort.Slice(identities, func(i, j int) bool {
+		if identities[i].Provider != identities[j].Provider {
+			return identities[i].Provider < identities[j].Provider
+		}
+		return identities[i].ExternalID < identities[j].ExternalID
+	})
+	return identities, nil
+}
+
+func (m *MemoryStore) DeleteUserIdentity(provider, externalID string) error {
+	defer m.lock()()
+
+	key := identityKey(provider, externalID)
+	if _, ok := m.state.identities[key]; !ok {
+		return apperr.ErrIdentityNotFound
+	}
+	delete(m.state.identities, key)
+	return nil
+}
+
+// Outbox methods
+func (m *MemoryStore) AddOutboxEvent(event *models.OutboxEvent) error {
+	defer m.lock()()
+
+	for _, e := range m.state.outbox {
+		if e.IdempotencyKey == event.IdempotencyKey {
+			return nil
+		}
+	}
+
+	m.state.lastOutboxID++
+	event.ID = m.state.lastOutboxID
+	event.Attempts = 0
+	event.LastError = ""
+	event.CreatedAt = m.now()
+	event.NextAttemptAt = event.CreatedAt
+	m.state.outbox = append(m.state.outbox, *event)
+	return nil
+}
+
+func (m *MemoryStore) ClaimOutboxEvents(now time.Time, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	defer m.lock()()
+
+	due := []int{}
+	for i, e := range m.state.outbox {
+		if !e.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		ea, eb := m.state.outbox[due[a]], m.state.outbox[due[b]]
+		if !ea.NextAttemptAt.Equal(eb.NextAttemptAt) {
+			return ea.NextAttemptAt.Before(eb.NextAttemptAt)
+		}
+		return ea.ID < eb.ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	events := []models.OutboxEvent{}
+	for _, i := range due {
+		m.state.outbox[i].NextAttemptAt = now.Add(lease)
+		events = append(events, m.state.outbox[i])
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (m *MemoryStore) CompleteOutboxEvent(outboxID int64) error {
+	defer m.lock()()
+
+	for i, e := range m.state.outbox {
+		if e.ID == outboxID {
+			m.state.outbox = append(m.state.outbox[:i:i], m.state.outbox[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) RetryOutboxEvent(outboxID int64, nextAttemptAt time.Time, lastError string) error {
+	defer m.lock()()
+
+	for i := range m.state.outbox {
+		if m.state.outbox[i].ID == outboxID {
+			m.state.outbox[i].Attempts++
+			m.state.outbox[i].NextAttemptAt = nextAttemptAt
+			m.state.outbox[i].LastError = lastError
+		}
+	}
+	return nil
+}
+
+// Webhook methods
+func (m *MemoryStore) CreateWebhookSubscription(sub *models.WebhookSubscription) error {
+	defer m.lock()()
+
+	if sub.TeamName != "" {
+		if _, ok := m.state.teams[sub.TeamName]; !ok {
+			return fmt.Errorf("team %q does not exist", sub.TeamName)
+		}
+	}
+
+	m.state.lastWebhookSubID++
+	sub.ID = m.state.lastWebhookSubID
+	sub.CreatedAt = m.now()
+	sub.EventTypes = append([]models.EventType{}, sub.EventTypes...)
+	m.state.webhookSubs = append(m.state.webhookSubs, *sub)
+	return nil
+}
+
+func (m *MemoryStore) GetWebhookSubscription(subscriptionID int64) (*models.WebhookSubscription, error) {
+	defer m.lock()()
+
+	for _, sub := range m.state.webhookSubs {
+		if sub.ID == subscriptionID {
+			sub.EventTypes = append([]models.EventType{}, sub.EventTypes...)
+			return &sub, nil
+		}
+	}
+	return nil, apperr.ErrWebhookNotFound
+}
+
+func (m *MemoryStore) ListWebhookSubscriptions(teamName string) ([]models.WebhookSubscription, error) {
+	defer m.lock()()
+
+	subs := []models.WebhookSubscription{}
+	for _, sub := range m.state.webhookSubs {
+		if teamName == "" || sub.TeamName == "" || sub.TeamName == teamName {
+			sub.EventTypes = append([]models.EventType{}, sub.EventTypes...)
+			subs = append(subs, sub)
+		}
+	}
+	return subs, nil
+}
+
+func (m *MemoryStore) DeleteWebhookSubscription(subscriptionID int64) error {
+	defer m.lock()()
+
+	found := false
+	subs := m.state.webhookSubs[:0:0]
+	for _, sub := range m.state.webhookSubs {
+		if sub.ID == subscriptionID {
+			found = true
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	if !found {
+		return apperr.ErrWebhookNotFound
+	}
+	m.state.webhookSubs = subs
+
+	// ON DELETE CASCADE
+	deliveries := m.state.webhookDeliveries[:0:0]
+	for _, d := range m.state.webhookDeliveries {
+		if d.SubscriptionID != subscriptionID {
+			deliveries = append(deliveries, d)
+		}
+	}
+	m.state.webhookDeliveries = deliveries
+
+	letters := m.state.webhookDeadLetters[:0:0]
+	for _, l := range m.state.webhookDeadLetters {
+		if l.SubscriptionID != subscriptionID {
+			letters = append(letters, l)
+		}
+	}
+	m.state.webhookDeadLetters = letters
+	return nil
+}
+
+func (m *MemoryStore) EnqueueWebhookDelivery(delivery *models.WebhookDelivery) error {
+	defer m.lock()()
+
+	if m.webhookSub(delivery.SubscriptionID) == nil {
+		return fmt.Errorf("webhook subscription %d does not exist", delivery.SubscriptionID)
+	}
+	for _, d := range m.state.webhookDeliveries {
+		if d.SubscriptionID == delivery.SubscriptionID && d.EventID == delivery.EventID {
+			return nil
+		}
+	}
+
+	m.state.lastDeliveryID++
+	delivery.ID = m.state.lastDeliveryID
+	delivery.Attempts = 0
+	delivery.LastError = ""
+	delivery.CreatedAt = m.now()
+	delivery.NextAttemptAt = delivery.CreatedAt
+	stored := *delivery
+	stored.URL, stored.Secret = "", ""
+	m.state.webhookDeliveries = append(m.state.webhookDeliveries, stored)
+	return nil
+}
+
+func (m *MemoryStore) ClaimWebhookDeliveries(now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	defer m.lock()()
+
+	due := []int{}
+	for i, d := range m.state.webhookDeliveries {
+		if !d.NextAttemptAt.After(now) {
+			due = append(due, i)
+		}
+	}
+	sort.SliceStable(due, func(a, b int) bool {
+		da, db := m.state.webhookDeliveries[due[a]], m.state.webhookDeliveries[due[b]]
+		if !da.NextAttemptAt.Equal(db.NextAttemptAt) {
+			return da.NextAttemptAt.Before(db.NextAttemptAt)
+		}
+		return da.ID < db.ID
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	deliveries := []models.WebhookDelivery{}
+	for _, i := range due {
+		m.state.webhookDeliveries[i].NextAttemptAt = now.Add(lease)
+
+		delivery := m.state.webhookDeliveries[i]
+		sub := m.webhookSub(delivery.SubscriptionID)
+		delivery.URL, delivery.Secret = sub.URL, sub.Secret
+		deliveries = append(deliveries, delivery)
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (m *MemoryStore) CompleteWebhookDelivery(deliveryID int64) error {
+	defer m.lock()()
+
+	if i := m.webhookDeliveryIndex(deliveryID); i >= 0 {
+		m.state.webhookDeliveries = append(m.state.webhookDeliveries[:i:i], m.state.webhookDeliveries[i+1:]...)
+	}
+	return nil
+}
+
+func (m *MemoryStore) RetryWebhookDelivery(deliveryID int64, nextAttemptAt time.Time, lastError string) error {
+	defer m.lock()()
+
+	if i := m.webhookDeliveryIndex(deliveryID); i >= 0 {
+		d := &m.state.webhookDeliveries[i]
+		d.Attempts++
+		d.NextAttemptAt = nextAttemptAt
+		d.LastError = lastError
+	}
+	return nil
+}
+
+func (m *MemoryStore) DeadLetterWebhookDelivery(deliveryID int64, lastError string) error {
+	defer m.lock()()
+
+	i := m.webhookDeliveryIndex(deliveryID)
+	if i < 0 {
+		return nil
+	}
+	d := m.state.webhookDeliveries[i]
+	m.state.webhookDeliveries = append(m.state.webhookDeliveries[:i:i], m.state.webhookDeliveries[i+1:]...)
+
+	m.state.lastDeadLetterID++
+	m.state.webhookDeadLetters = append(m.state.webhookDeadLetters, models.WebhookDeadLetter{
+		ID:             m.state.lastDeadLetterID,
+		SubscriptionID: d.SubscriptionID,
+		EventID:        d.EventID,
+		EventType:      d.EventType,
+		Payload:        d.Payload,
+		Attempts:       d.Attempts + 1,
+		LastError:      lastError,
+		CreatedAt:      d.CreatedAt,
+		FailedAt:       m.now(),
+	})
+	return nil
+}
+
+func (m *MemoryStore) ListWebhookDeadLetters(subscriptionID int64) ([]models.WebhookDeadLetter, error) {
+	defer m.lock()()
+
+	letters := []models.WebhookDeadLetter{}
+	for _, l := range m.state.webhookDeadLetters {
+		if subscriptionID == 0 || l.SubscriptionID == subscriptionID {
+			letters = append(letters, l)
+		}
+	}
+	return letters, nil
+}
+
+func (m *MemoryStore) RequeueWebhookDeadLetter(deadLetterID int64) (*models.WebhookDelivery, error) {
+	defer m.lock()()
+
+	for i, l := range m.state.webhookDeadLetters {
+		if l.ID != deadLetterID {
+			continue
+		}
+		m.state.webhookDeadLetters = append(m.state.webhookDeadLetters[:i:i], m.state.webhookDeadLetters[i+1:]...)
+
+		m.state.lastDeliveryID++
+		now := m.now()
+		delivery := models.WebhookDelivery{
+			ID:             m.state.lastDeliveryID,
+			SubscriptionID: l.SubscriptionID,
+			EventID:        l.EventID,
+			EventType:      l.EventType,
+			Payload:        l.Payload,
+			NextAttemptAt:  now,
+			CreatedAt:      now,
+		}
+		m.state.webhookDeliveries = append(m.state.webhookDeliveries, delivery)
+		return &delivery, nil
+	}
+	return nil, apperr.ErrDeadLetterNotFound
+}
+
+func (m *MemoryStore) webhookSub(subscriptionID int64) *models.WebhookSubscription {
+	for i := range m.state.webhookSubs {
+		if m.state.webhookSubs[i].ID == subscriptionID {
+			return &m.state.webhookSubs[i]
+		}
+	}
+	return nil
+}
+
+func (m *MemoryStore) webhookDeliveryIndex(deliveryID int64) int {
+	for i, d := range m.state.webhookDeliveries {
+		if d.ID == deliveryID {
+			return i
+		}
+	}
+	return -1
+}
+
+func copyStrategyParams(params models.StrategyParams) models.StrategyParams {
+	var c models.StrategyParams
+	if params.Weights != nil {
+		c.Weights = make(map[string]int, len(params.Weights))
+		for k, v := range params.Weights {
+			c.Weights[k] = v
+		}
+	}
+	if params.Expertise != nil {
+		c.Expertise = make(map[string][]string, len(params.Expertise))
+		for k, v := range params.Expertise {
+			c.Expertise[k] = append([]string(nil), v...)
+		}
+	}
+	return c
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
@@ -0,0 +1,105 @@
+package repo
+
+import (
+	"errors"
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMemoryTeam(t *testing.T, store *MemoryStore) {
+	t.Helper()
+	require.NoError(t, store.CreateTeam(&models.Team{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}))
+}
+
+func TestMemoryStoreWithTxRollback(t *testing.T) {
+	store := NewMemoryStore()
+	newMemoryTeam(t, store)
+
+	errAbort := errors.New("abort")
+	err := store.WithTx(func(tx Store) error {
+		require.NoError(t, tx.CreatePR(&models.PullRequest{
+			PullRequestID:     "pr-1",
+			AuthorID:          "u1",
+			Status:            models.StatusOpen,
+			AssignedReviewers: []string{"u2"},
+		}))
+		require.NoError(t, tx.SetUserActive("u2", false))
+
+		// Вложенная транзакция видит незафиксированные изменения
+		return tx.WithTx(func(tx Store) error {
+			_, err := tx.GetPR("pr-1")
+			require.NoError(t, err)
+			return errAbort
+		})
+	})
+	assert.ErrorIs(t, err, errAbort)
+
+	_, err = store.GetPR("pr-1")
+	assert.ErrorIs(t, err, apperr.ErrPRNotFound)
+
+	user, err := store.GetUser("u2")
+	require.NoError(t, err)
+	assert.True(t, user.IsActive)
+}
+
+func TestMemoryStoreWithTxCommit(t *testing.T) {
+	store := NewMemoryStore()
+	newMemoryTeam(t, store)
+
+	err := store.WithTx(func(tx Store) error {
+		return tx.CreatePR(&models.PullRequest{PullRequestID: "pr-1", AuthorID: "u1", Status: models.StatusOpen})
+	})
+	require.NoError(t, err)
+
+	pr, err := store.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{}, pr.AssignedReviewers)
+
+	err = store.CreatePR(&models.PullRequest{PullRequestID: "pr-1", AuthorID: "u1", Status: models.StatusOpen})
+	assert.ErrorIs(t, err, apperr.ErrPRExists)
+}
+
+func TestMemoryStoreReviewerStates(t *testing.T) {
+	store := NewMemoryStore()
+	newMemoryTeam(t, store)
+
+	pr := &models.PullRequest{
+		PullRequestID:     "pr-1",
+		AuthorID:          "u1",
+		Status:            models.StatusOpen,
+		AssignedReviewers: []string{"u2"},
+	}
+	require.NoError(t, store.CreatePR(pr))
+	require.NoError(t, store.SubmitReview("pr-1", "u2", models.VerdictApproved, ""))
+	assert.ErrorIs(t, store.SubmitReview("pr-1", "u1", models.VerdictApproved, ""), apperr.ErrNotAssigned)
+
+	ids, err := store.GetOpenPRIDsByReviewer("u2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pr-1"}, ids)
+
+	// Снятый ревьюер теряет вердикт, повторно назначенный начинает заново
+	pr.AssignedReviewers = []string{}
+	require.NoError(t, store.UpdatePRReviewers(pr))
+	pr.AssignedReviewers = []string{"u2"}
+	require.NoError(t, store.UpdatePRReviewers(pr))
+
+	stored, err := store.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, stored.AssignedReviewers)
+	assert.Empty(t, stored.Reviews)
+
+	require.NoError(t, store.MergePR("pr-1"))
+	ids, err = store.GetOpenPRIDsByReviewer("u2")
+	require.NoError(t, err)
+	assert.Empty(t, ids)
+}
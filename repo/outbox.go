@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"database/sql"
+	"reviewtask/models"
+	"sort"
+	"time"
+)
+
+// AddOutboxEvent записывает событие в outbox. Чтобы событие фиксировалось
+// вместе с изменением, вызывайте его на репозитории из WithTx.
+func (r *Repository) AddOutboxEvent(event *models.OutboxEvent) error {
+	err := r.q.QueryRow(
+		`INSERT INTO outbox_events (idempotency_key, event_id, event_type, team_name, payload)
+     VALUES ($1, $2, $3, $4, $5)
+     ON CONFLICT (idempotency_key) DO NOTHING
+     RETURNING id, attempts, next_attempt_at, created_at`,
+		event.IdempotencyKey, event.EventID, event.EventType, nullString(event.TeamName), []byte(event.Payload),
+	).Scan(&event.ID, &event.Attempts, &event.NextAttemptAt, &event.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+func (r *Repository) ClaimOutboxEvents(now time.Time, limit int, lease time.Duration) ([]models.OutboxEvent, error) {
+	rows, err := r.q.Query(
+		`UPDATE outbox_events
+     SET next_attempt_at = $2
+     WHERE id IN (
+       SELECT id FROM outbox_events
+       WHERE next_attempt_at <= $1
+       ORDER BY next_attempt_at, id
+       LIMIT $3
+       FOR UPDATE SKIP LOCKED
+     )
+     RETURNING id, idempotency_key, event_id, event_type, team_name, payload,
+       attempts, next_attempt_at, last_error, created_at`,
+		now, now.Add(lease), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.OutboxEvent{}
+	for rows.Next() {
+		var event models.OutboxEvent
+		var teamName, lastError sql.NullString
+		err := rows.Scan(
+			&event.ID, &event.IdempotencyKey, &event.EventID, &event.EventType, &teamName, &event.Payload,
+			&event.Attempts, &event.NextAttemptAt, &lastError, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.TeamName = teamName.String
+		event.LastError = lastError.String
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Публикуем в порядке записи; RETURNING порядок не сохраняет
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+	return events, nil
+}
+
+func (r *Repository) CompleteOutboxEvent(outboxID int64) error {
+	_, err := r.q.Exec("DELETE FROM outbox_events WHERE id = $1", outboxID)
+	return err
+}
+
+func (r *Repository) RetryOutboxEvent(outboxID int64, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.q.Exec(
+		`UPDATE outbox_events
+     SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+     WHERE id = $1`,
+		outboxID, nextAttemptAt, nullString(lastError),
+	)
+	return err
+}
@@ -0,0 +1,119 @@
+package repo
+
+import (
+	"reviewtask/models"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// prFilterCondition — условия PRFilter без курсора; параметры:
+// $1 статусы, $2 author_id, $3 команда автора, $4 ревьюер, $5 подстрока названия,
+// $6/$7 окно created_at, $8/$9 окно merged_at
+const prFilterCondition = `
+    (cardinality($1::text[]) = 0 OR pr.status = ANY($1::text[]))
+    AND ($2 = '' OR pr.author_id = $2)
+    AND ($3 = '' OR u.team_name = $3)
+    AND ($4 = '' OR EXISTS (
+      SELECT 1 FROM pr_reviewers rv
+      WHERE rv.pull_request_id = pr.pull_request_id AND rv.user_id = $4 AND rv.state = 'ASSIGNED'
+    ))
+    AND ($5 = '' OR pr.pull_request_name ILIKE '%' || $5 || '%')
+    AND ($6::timestamp IS NULL OR pr.created_at >= $6)
+    AND ($7::timestamp IS NULL OR pr.created_at < $7)
+    AND ($8::timestamp IS NULL OR pr.merged_at >= $8)
+    AND ($9::timestamp IS NULL OR pr.merged_at < $9)`
+
+// likeEscaper экранирует спецсимволы LIKE в подстроке поиска
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// ListPRs возвращает страницу PR в порядке (поле сортировки, pull_request_id)
+// и общее число PR под фильтром
+func (r *Repository) ListPRs(filter models.PRFilter) (*models.PRPage, error) {
+	statuses := make([]string, len(filter.Statuses))
+	for i, status := range filter.Statuses {
+		statuses[i] = string(status)
+	}
+
+	condition := prFilterCondition
+	sortColumn := "pr.created_at"
+	if filter.SortBy == models.SortByMergedAt {
+		condition += " AND pr.merged_at IS NOT NULL"
+		sortColumn = "pr.merged_at"
+	}
+
+	args := []interface{}{
+		pq.Array(statuses), filter.AuthorID, filter.TeamName, filter.ReviewerID, likeEscaper.Replace(filter.NameContains),
+		filter.CreatedFrom, filter.CreatedTo, filter.MergedFrom, filter.MergedTo,
+	}
+
+	page := &models.PRPage{PullRequests: []models.PullRequestShort{}}
+	err := r.q.QueryRow(`
+    SELECT COUNT(*)
+    FROM pull_requests pr
+    JOIN users u ON u.user_id = pr.author_id
+    WHERE`+condition, args...,
+	).Scan(&page.Total)
+	if err != nil {
+		return nil, err
+	}
+
+	direction, cmp := "DESC", "<"
+	if filter.Order == models.SortAsc {
+		direction, cmp = "ASC", ">"
+	}
+
+	var cursorTime *time.Time
+	var cursorID string
+	if filter.Cursor != nil {
+		cursorTime, cursorID = &filter.Cursor.Time, filter.Cursor.ID
+	}
+
+	query := `
+    SELECT pr.pull_request_id, pr.pull_request_name, pr.author_id, pr.status, pr.created_at, pr.merged_at
+    FROM pull_requests pr
+    JOIN users u ON u.user_id = pr.author_id
+    WHERE` + condition + `
+      AND ($10::timestamp IS NULL OR (` + sortColumn + `, pr.pull_request_id) ` + cmp + ` ($10, $11))
+    ORDER BY ` + sortColumn + ` ` + direction + `, pr.pull_request_id ` + direction + `
+    LIMIT $12
+  `
+
+	// Лишняя запись показывает, есть ли следующая страница
+	rows, err := r.q.Query(query, append(args, cursorTime, cursorID, filter.Limit+1)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var pr models.PullRequestShort
+		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status, &pr.CreatedAt, &pr.MergedAt); err != nil {
+			return nil, err
+		}
+		page.PullRequests = append(page.PullRequests, pr)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	trimPage(page, filter)
+	return page, nil
+}
+
+// trimPage отрезает лишнюю запись страницы и выставляет курсор следующей
+func trimPage(page *models.PRPage, filter models.PRFilter) {
+	if len(page.PullRequests) <= filter.Limit {
+		return
+	}
+
+	page.PullRequests = page.PullRequests[:filter.Limit]
+	last := page.PullRequests[filter.Limit-1]
+	page.NextCursor = models.PageCursor{
+		SortBy: filter.SortBy,
+		Order:  filter.Order,
+		Time:   last.SortKey(filter.SortBy),
+		ID:     last.PullRequestID,
+	}.Encode()
+}
@@ -13,6 +13,17 @@ func TestRepositoryHelpers(t *testing.T) {
 		assert.False(t, containsString([]string{}, "a"))
 	})
 
+	t.Run("nullString helper", func(t *testing.T) {
+		assert.False(t, nullString("").Valid)
+		assert.True(t, nullString("u1").Valid)
+		assert.Equal(t, "u1", nullString("u1").String)
+	})
+
+	t.Run("likeEscaper helper", func(t *testing.T) {
+		assert.Equal(t, `100\% search\_index`, likeEscaper.Replace("100% search_index"))
+		assert.Equal(t, `a\\b`, likeEscaper.Replace(`a\b`))
+	})
+
 	t.Run("Repository creation", func(t *testing.T) {
 		// Просто проверяем что структура создается
 		repo := &Repository{}
@@ -2,52 +2,122 @@ package repo
 
 import (
 	"database/sql"
+	"encoding/json"
 
 	"math/rand"
+	"reviewtask/apperr"
 	"reviewtask/models"
-	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
+// dbtx — общие методы *sql.DB и *sql.Tx
+type dbtx interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	Query(query string, args ...interface{}) (*sql.Rows, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
 type Repository struct {
 	DB *sql.DB
+
+	// q — соединение, через которое выполняются запросы: DB или открытая транзакция
+	q  dbtx
+	tx *sql.Tx
 }
 
 func NewRepository(db *sql.DB) *Repository {
 	rand.Seed(time.Now().UnixNano())
-	return &Repository{DB: db}
+	return &Repository{DB: db, q: db}
 }
 
-// Team methods
-func (r *Repository) CreateTeam(team *models.Team) error {
+// WithTx выполняет fn в транзакции: все методы переданного хранилища
+// работают в ней. Если репозиторий уже привязан к транзакции, fn
+// выполняется в ней же, а фиксация остаётся за внешним вызовом.
+func (r *Repository) WithTx(fn func(tx Store) error) error {
+	return r.withTx(func(tx *Repository) error {
+		return fn(tx)
+	})
+}
+
+func (r *Repository) withTx(fn func(tx *Repository) error) error {
+	if r.tx != nil {
+		return fn(r)
+	}
+
 	tx, err := r.DB.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	_, err = tx.Exec("INSERT INTO teams (team_name) VALUES ($1)", team.TeamName)
+	if err := fn(&Repository{DB: r.DB, q: tx, tx: tx}); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Team methods
+func (r *Repository) CreateTeam(team *models.Team) error {
+	if team.ReviewerStrategy == "" {
+		team.ReviewerStrategy = models.StrategyRandom
+	}
+
+	params, err := json.Marshal(team.StrategyParams)
 	if err != nil {
 		return err
 	}
 
-	for _, member := range team.Members {
-		_, err = tx.Exec(
-			"INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)",
-			member.UserID, member.Username, team.TeamName, member.IsActive,
+	return r.withTx(func(tx *Repository) error {
+		_, err := tx.q.Exec(
+			"INSERT INTO teams (team_name, reviewer_strategy, reviewer_strategy_params) VALUES ($1, $2, $3)",
+			team.TeamName, team.ReviewerStrategy, params,
 		)
 		if err != nil {
 			return err
 		}
-	}
 
-	return tx.Commit()
+		for _, member := range team.Members {
+			_, err = tx.q.Exec(
+				"INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)",
+				member.UserID, member.Username, team.TeamName, member.IsActive,
+			)
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.insertTeamPartners(team.TeamName, team.PartnerTeams)
+	})
 }
 
 func (r *Repository) GetTeam(teamName string) (*models.Team, error) {
 	team := &models.Team{TeamName: teamName}
 
-	rows, err := r.DB.Query(
+	var params []byte
+	err := r.q.QueryRow(
+		"SELECT reviewer_strategy, reviewer_strategy_params FROM teams WHERE team_name = $1",
+		teamName,
+	).Scan(&team.ReviewerStrategy, &params)
+	if err == sql.ErrNoRows {
+		return nil, apperr.ErrTeamNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(params, &team.StrategyParams); err != nil {
+		return nil, err
+	}
+
+	team.PartnerTeams, err = r.getTeamPartners(teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.q.Query(
 		"SELECT user_id, username, is_active FROM users WHERE team_name = $1",
 		teamName,
 	)
@@ -67,66 +137,258 @@ func (r *Repository) GetTeam(teamName string) (*models.Team, error) {
 	return team, nil
 }
 
+func (r *Repository) getTeamPartners(teamName string) ([]string, error) {
+	rows, err := r.q.Query(
+		"SELECT partner_team FROM team_partners WHERE team_name = $1 ORDER BY priority, partner_team",
+		teamName,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var partners []string
+	for rows.Next() {
+		var partner string
+		if err := rows.Scan(&partner); err != nil {
+			return nil, err
+		}
+		partners = append(partners, partner)
+	}
+
+	return partners, rows.Err()
+}
+
+// SetTeamPartners заменяет список команд-партнёров; порядок задаёт приоритет
+func (r *Repository) SetTeamPartners(teamName string, partnerTeams []string) error {
+	return r.withTx(func(tx *Repository) error {
+		if _, err := tx.q.Exec("DELETE FROM team_partners WHERE team_name = $1", teamName); err != nil {
+			return err
+		}
+
+		return tx.insertTeamPartners(teamName, partnerTeams)
+	})
+}
+
+func (r *Repository) insertTeamPartners(teamName string, partnerTeams []string) error {
+	for priority, partner := range partnerTeams {
+		_, err := r.q.Exec(
+			"INSERT INTO team_partners (team_name, partner_team, priority) VALUES ($1, $2, $3)",
+			teamName, partner, priority,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateTeamStrategy меняет стратегию выбора ревьюеров команды и её параметры
+func (r *Repository) UpdateTeamStrategy(teamName string, strategy models.ReviewerStrategy, params models.StrategyParams) error {
+	paramsJSON, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+
+	res, err := r.q.Exec(
+		"UPDATE teams SET reviewer_strategy = $1, reviewer_strategy_params = $2 WHERE team_name = $3",
+		strategy, paramsJSON, teamName,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperr.ErrTeamNotFound
+	}
+	return nil
+}
+
+// GetTeamSettings возвращает настройки команды; если они не сохранены — настройки по умолчанию
+func (r *Repository) GetTeamSettings(teamName string) (*models.TeamSettings, error) {
+	var exists bool
+	err := r.q.QueryRow("SELECT EXISTS(SELECT 1 FROM teams WHERE team_name = $1)", teamName).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, apperr.ErrTeamNotFound
+	}
+
+	settings := &models.TeamSettings{TeamName: teamName}
+	err = r.q.QueryRow(
+		`SELECT min_reviewers, max_reviewers, allow_cross_team, fail_on_insufficient,
+            required_approvals, block_on_changes_requested, require_reviewer, disallow_self_merge
+     FROM team_settings WHERE team_name = $1`,
+		teamName,
+	).Scan(
+		&settings.MinReviewers,
+		&settings.MaxReviewers,
+		&settings.AllowCrossTeam,
+		&settings.FailOnInsufficient,
+		&settings.RequiredApprovals,
+		&settings.BlockOnChangesRequested,
+		&settings.RequireReviewer,
+		&settings.DisallowSelfMerge,
+	)
+	if err == sql.ErrNoRows {
+		return models.DefaultTeamSettings(teamName), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (r *Repository) UpsertTeamSettings(settings *models.TeamSettings) error {
+	_, err := r.q.Exec(
+		`INSERT INTO team_settings (team_name, min_reviewers, max_reviewers, allow_cross_team, fail_on_insufficient,
+                                required_approvals, block_on_changes_requested, require_reviewer, disallow_self_merge)
+     VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+     ON CONFLICT (team_name) DO UPDATE SET
+       min_reviewers = EXCLUDED.min_reviewers,
+       max_reviewers = EXCLUDED.max_reviewers,
+       allow_cross_team = EXCLUDED.allow_cross_team,
+       fail_on_insufficient = EXCLUDED.fail_on_insufficient,
+       required_approvals = EXCLUDED.required_approvals,
+       block_on_changes_requested = EXCLUDED.block_on_changes_requested,
+       require_reviewer = EXCLUDED.require_reviewer,
+       disallow_self_merge = EXCLUDED.disallow_self_merge,
+       updated_at = NOW()`,
+		settings.TeamName, settings.MinReviewers, settings.MaxReviewers,
+		settings.AllowCrossTeam, settings.FailOnInsufficient,
+		settings.RequiredApprovals, settings.BlockOnChangesRequested,
+		settings.RequireReviewer, settings.DisallowSelfMerge,
+	)
+	return err
+}
+
 // User methods
 func (r *Repository) SetUserActive(userID string, isActive bool) error {
-	_, err := r.DB.Exec(
+	_, err := r.q.Exec(
 		"UPDATE users SET is_active = $1 WHERE user_id = $2",
 		isActive, userID,
 	)
 	return err
 }
 
+// GetUser возвращает пользователя; у исключённого из команды TeamName пустой
 func (r *Repository) GetUser(userID string) (*models.User, error) {
 	user := &models.User{}
-	err := r.DB.QueryRow(
+	var teamName sql.NullString
+	err := r.q.QueryRow(
 		"SELECT user_id, username, team_name, is_active FROM users WHERE user_id = $1",
 		userID,
-	).Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive)
-
+	).Scan(&user.UserID, &user.Username, &teamName, &user.IsActive)
+	if err == sql.ErrNoRows {
+		return nil, apperr.ErrUserNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
+	user.TeamName = teamName.String
 	return user, nil
 }
 
-// PR methods - обновляем для работы со строковыми ID
+func (r *Repository) CreateUser(user *models.User) error {
+	_, err := r.q.Exec(
+		"INSERT INTO users (user_id, username, team_name, is_active) VALUES ($1, $2, $3, $4)",
+		user.UserID, user.Username, nullString(user.TeamName), user.IsActive,
+	)
+	return err
+}
+
+// UpdateUser сохраняет имя, команду и активность пользователя.
+// Пустой TeamName исключает пользователя из команды.
+func (r *Repository) UpdateUser(user *models.User) error {
+	_, err := r.q.Exec(
+		"UPDATE users SET username = $1, team_name = $2, is_active = $3 WHERE user_id = $4",
+		user.Username, nullString(user.TeamName), user.IsActive, user.UserID,
+	)
+	return err
+}
+
+// Состояния записи в pr_reviewers
+const (
+	reviewerAssigned = "ASSIGNED"
+	reviewerReplaced = "REPLACED"
+)
+
+// PR methods - ревьюеры хранятся в таблице pr_reviewers
 func (r *Repository) CreatePR(pr *models.PullRequest) error {
-	query := `
-    INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status, assigned_reviewers) 
-    VALUES ($1, $2, $3, $4, $5) 
-    RETURNING created_at
-  `
-	reviewersStr := strings.Join(pr.AssignedReviewers, ",")
-	return r.DB.QueryRow(
-		query,
-		pr.PullRequestID,
-		pr.PullRequestName,
-		pr.AuthorID,
-		pr.Status,
-		reviewersStr,
-	).Scan(&pr.CreatedAt)
+	return r.withTx(func(tx *Repository) error {
+		query := `
+      INSERT INTO pull_requests (pull_request_id, pull_request_name, author_id, status) 
+      VALUES ($1, $2, $3, $4) 
+      ON CONFLICT (pull_request_id) DO NOTHING
+      RETURNING created_at
+    `
+		err := tx.q.QueryRow(
+			query,
+			pr.PullRequestID,
+			pr.PullRequestName,
+			pr.AuthorID,
+			pr.Status,
+		).Scan(&pr.CreatedAt)
+		if err == sql.ErrNoRows {
+			return apperr.ErrPRExists
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, reviewerID := range pr.AssignedReviewers {
+			fallback := containsString(pr.FallbackReviewers, reviewerID)
+			if err := tx.assignReviewer(pr.PullRequestID, reviewerID, fallback); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func (r *Repository) GetPR(pullRequestID string) (*models.PullRequest, error) {
+	return r.getPR(pullRequestID, false)
+}
+
+// GetPRForUpdate читает PR и блокирует его строку до конца транзакции,
+// чтобы параллельные изменения того же PR выполнялись по очереди.
+// Вызывается только внутри WithTx.
+func (r *Repository) GetPRForUpdate(pullRequestID string) (*models.PullRequest, error) {
+	return r.getPR(pullRequestID, true)
+}
+
+func (r *Repository) getPR(pullRequestID string, forUpdate bool) (*models.PullRequest, error) {
 	pr := &models.PullRequest{}
-	var reviewersStr string
-	var mergedAt sql.NullTime
+	var mergedAt, closedAt sql.NullTime
 
 	query := `
-    SELECT pull_request_id, pull_request_name, author_id, status, assigned_reviewers, created_at, merged_at
+    SELECT pull_request_id, pull_request_name, author_id, status, created_at, merged_at, closed_at
     FROM pull_requests 
     WHERE pull_request_id = $1
   `
+	if forUpdate {
+		query += "FOR UPDATE"
+	}
 
-	err := r.DB.QueryRow(query, pullRequestID).Scan(
+	err := r.q.QueryRow(query, pullRequestID).Scan(
 		&pr.PullRequestID,
 		&pr.PullRequestName,
 		&pr.AuthorID,
 		&pr.Status,
-		&reviewersStr,
 		&pr.CreatedAt,
 		&mergedAt,
+		&closedAt,
 	)
+	if err == sql.ErrNoRows {
+		return nil, apperr.ErrPRNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -134,68 +396,180 @@ func (r *Repository) GetPR(pullRequestID string) (*models.PullRequest, error) {
 	if mergedAt.Valid {
 		pr.MergedAt = &mergedAt.Time
 	}
+	if closedAt.Valid {
+		pr.ClosedAt = &closedAt.Time
+	}
 
-	pr.AssignedReviewers = strings.Split(reviewersStr, ",")
-	if len(pr.AssignedReviewers) == 1 && pr.AssignedReviewers[0] == "" {
-		pr.AssignedReviewers = []string{}
+	if err := r.loadPRReviewers(pr); err != nil {
+		return nil, err
 	}
 
 	return pr, nil
 }
 
+func (r *Repository) loadPRReviewers(pr *models.PullRequest) error {
+	rows, err := r.q.Query(
+		`SELECT user_id, is_fallback, verdict, verdict_comment, verdict_at FROM pr_reviewers
+     WHERE pull_request_id = $1 AND state = $2
+     ORDER BY assigned_at, user_id`,
+		pr.PullRequestID, reviewerAssigned,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	pr.AssignedReviewers = []string{}
+	pr.FallbackReviewers = nil
+	pr.Reviews = nil
+	for rows.Next() {
+		var userID string
+		var fallback bool
+		var verdict, comment sql.NullString
+		var verdictAt sql.NullTime
+		if err := rows.Scan(&userID, &fallback, &verdict, &comment, &verdictAt); err != nil {
+			return err
+		}
+		pr.AssignedReviewers = append(pr.AssignedReviewers, userID)
+		if fallback {
+			pr.FallbackReviewers = append(pr.FallbackReviewers, userID)
+		}
+		if verdict.Valid {
+			pr.Reviews = append(pr.Reviews, models.Review{
+				UserID:      userID,
+				Verdict:     models.ReviewVerdict(verdict.String),
+				Comment:     comment.String,
+				SubmittedAt: verdictAt.Time,
+			})
+		}
+	}
+
+	return rows.Err()
+}
+
 func (r *Repository) MergePR(pullRequestID string) error {
-	_, err := r.DB.Exec(
+	_, err := r.q.Exec(
 		"UPDATE pull_requests SET status = 'MERGED', merged_at = NOW() WHERE pull_request_id = $1",
 		pullRequestID,
 	)
 	return err
 }
 
-func (r *Repository) UpdatePRReviewers(pr *models.PullRequest) error {
-	reviewersStr := strings.Join(pr.AssignedReviewers, ",")
-	_, err := r.DB.Exec(
-		"UPDATE pull_requests SET assigned_reviewers = $1 WHERE pull_request_id = $2",
-		reviewersStr, pr.PullRequestID,
+// UpdatePRStatus переводит PR в новый статус (кроме MERGED — см. MergePR)
+func (r *Repository) UpdatePRStatus(pullRequestID string, status models.PRStatus) error {
+	var closedAt interface{}
+	if status == models.StatusClosed {
+		closedAt = time.Now()
+	}
+
+	_, err := r.q.Exec(
+		"UPDATE pull_requests SET status = $1, closed_at = $2, updated_at = NOW() WHERE pull_request_id = $3",
+		status, closedAt, pullRequestID,
 	)
 	return err
 }
 
-func (r *Repository) GetPRsByReviewer(userID string) ([]models.PullRequestShort, error) {
-	query := `
-    SELECT pull_request_id, pull_request_name, author_id, status
-    FROM pull_requests 
-    WHERE $1 = ANY(STRING_TO_ARRAY(assigned_reviewers, ','))
-    ORDER BY created_at DESC
-  `
+// UpdatePRReviewers приводит набор назначенных ревьюеров к pr.AssignedReviewers:
+// снятые помечаются как REPLACED, новые добавляются как ASSIGNED.
+func (r *Repository) UpdatePRReviewers(pr *models.PullRequest) error {
+	return r.withTx(func(tx *Repository) error {
+		_, err := tx.q.Exec(
+			`UPDATE pr_reviewers SET state = $1
+       WHERE pull_request_id = $2 AND state = $3 AND NOT (user_id = ANY($4))`,
+			reviewerReplaced, pr.PullRequestID, reviewerAssigned, pq.Array(pr.AssignedReviewers),
+		)
+		if err != nil {
+			return err
+		}
+
+		for _, reviewerID := range pr.AssignedReviewers {
+			fallback := containsString(pr.FallbackReviewers, reviewerID)
+			if err := tx.assignReviewer(pr.PullRequestID, reviewerID, fallback); err != nil {
+				return err
+			}
+		}
 
-	rows, err := r.DB.Query(query, userID)
+		_, err = tx.q.Exec("UPDATE pull_requests SET updated_at = NOW() WHERE pull_request_id = $1", pr.PullRequestID)
+		return err
+	})
+}
+
+// SubmitReview сохраняет вердикт назначенного ревьюера, заменяя предыдущий
+func (r *Repository) SubmitReview(pullRequestID, userID string, verdict models.ReviewVerdict, comment string) error {
+	res, err := r.q.Exec(
+		`UPDATE pr_reviewers SET verdict = $1, verdict_comment = $2, verdict_at = NOW()
+     WHERE pull_request_id = $3 AND user_id = $4 AND state = $5`,
+		verdict, comment, pullRequestID, userID, reviewerAssigned,
+	)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperr.ErrNotAssigned
+	}
+	return nil
+}
+
+// assignReviewer назначает ревьюера; повторное назначение ранее снятого
+// ревьюера возвращает запись в состояние ASSIGNED.
+func (r *Repository) assignReviewer(pullRequestID, userID string, fallback bool) error {
+	_, err := r.q.Exec(
+		`INSERT INTO pr_reviewers (pull_request_id, user_id, state, is_fallback)
+     VALUES ($1, $2, $3, $4)
+     ON CONFLICT (pull_request_id, user_id)
+     DO UPDATE SET state = EXCLUDED.state, is_fallback = EXCLUDED.is_fallback, assigned_at = NOW(),
+                   verdict = NULL, verdict_comment = NULL, verdict_at = NULL
+     WHERE pr_reviewers.state <> EXCLUDED.state`,
+		pullRequestID, userID, reviewerAssigned, fallback,
+	)
+	return err
+}
+
+// GetOpenPRIDsByReviewer возвращает открытые PR, на которые назначен пользователь
+func (r *Repository) GetOpenPRIDsByReviewer(userID string) ([]string, error) {
+	rows, err := r.q.Query(
+		`SELECT pr.pull_request_id
+     FROM pr_reviewers rv
+     JOIN pull_requests pr ON pr.pull_request_id = rv.pull_request_id
+     WHERE rv.user_id = $1 AND rv.state = $2 AND pr.status IN ($3, $4)
+     ORDER BY pr.created_at, pr.pull_request_id`,
+		userID, reviewerAssigned, models.StatusOpen, models.StatusReopened,
+	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var prs []models.PullRequestShort
+	prIDs := []string{}
 	for rows.Next() {
-		var pr models.PullRequestShort
-		if err := rows.Scan(&pr.PullRequestID, &pr.PullRequestName, &pr.AuthorID, &pr.Status); err != nil {
+		var prID string
+		if err := rows.Scan(&prID); err != nil {
 			return nil, err
 		}
-		prs = append(prs, pr)
+		prIDs = append(prIDs, prID)
 	}
 
-	return prs, nil
+	return prIDs, rows.Err()
 }
 
 // Business logic
+
+// GetActiveUsersByTeam возвращает активных участников команды, которые сейчас
+// не в отсутствии (см. user_absences)
 func (r *Repository) GetActiveUsersByTeam(teamName string, excludeUserIDs []string) ([]models.User, error) {
 	query := `
     SELECT user_id, username, team_name, is_active 
     FROM users 
-    WHERE team_name = $1 AND is_active = true
+    WHERE team_name = $1 AND is_active = true AND ` + availableUserCondition + `
     ORDER BY user_id
   `
 
-	rows, err := r.DB.Query(query, teamName)
+	rows, err := r.q.Query(query, teamName)
 	if err != nil {
 		return nil, err
 	}
@@ -217,6 +591,86 @@ func (r *Repository) GetActiveUsersByTeam(teamName string, excludeUserIDs []stri
 	return users, nil
 }
 
+// GetActiveUsersOutsideTeam возвращает активных пользователей всех остальных команд
+func (r *Repository) GetActiveUsersOutsideTeam(teamName string, excludeUserIDs []string) ([]models.User, error) {
+	query := `
+    SELECT user_id, username, team_name, is_active 
+    FROM users 
+    WHERE team_name <> $1 AND is_active = true AND NOT (user_id = ANY($2)) AND ` + availableUserCondition + `
+    ORDER BY user_id
+  `
+
+	rows, err := r.q.Query(query, teamName, pq.Array(excludeUserIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		var user models.User
+		if err := rows.Scan(&user.UserID, &user.Username, &user.TeamName, &user.IsActive); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// GetOpenReviewCounts возвращает число открытых PR, на которые назначен каждый из пользователей
+func (r *Repository) GetOpenReviewCounts(userIDs []string) (map[string]int, error) {
+	query := `
+    SELECT rv.user_id, COUNT(*)
+    FROM pr_reviewers rv
+    JOIN pull_requests pr ON pr.pull_request_id = rv.pull_request_id
+    WHERE rv.user_id = ANY($1) AND rv.state = $2 AND pr.status IN ($3, $4)
+    GROUP BY rv.user_id
+  `
+
+	rows, err := r.q.Query(query, pq.Array(userIDs), reviewerAssigned, models.StatusOpen, models.StatusReopened)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int, len(userIDs))
+	for rows.Next() {
+		var userID string
+		var count int
+		if err := rows.Scan(&userID, &count); err != nil {
+			return nil, err
+		}
+		counts[userID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// GetLastAssignedAt возвращает время последнего назначения ревьюером для каждого из пользователей
+func (r *Repository) GetLastAssignedAt(userIDs []string) (map[string]time.Time, error) {
+	rows, err := r.q.Query(
+		"SELECT user_id, MAX(assigned_at) FROM pr_reviewers WHERE user_id = ANY($1) GROUP BY user_id",
+		pq.Array(userIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lastAssigned := make(map[string]time.Time, len(userIDs))
+	for rows.Next() {
+		var userID string
+		var assignedAt time.Time
+		if err := rows.Scan(&userID, &assignedAt); err != nil {
+			return nil, err
+		}
+		lastAssigned[userID] = assignedAt
+	}
+
+	return lastAssigned, rows.Err()
+}
+
 func (r *Repository) GetRandomReviewers(users []models.User, count int) []string {
 	if len(users) == 0 || count <= 0 {
 		return []string{}
@@ -0,0 +1,172 @@
+package repo
+
+import (
+	"database/sql"
+	"reviewtask/models"
+)
+
+// Все запросы статистики принимают параметры $1 (from), $2 (to), $3 (team_name);
+// NULL или пустая строка отключают соответствующий фильтр.
+
+// GetUserStats — назначения на ревью по пользователям; окно применяется к assigned_at
+func (r *Repository) GetUserStats(filter models.StatsFilter) ([]models.UserStats, error) {
+	query := `
+    SELECT u.user_id, u.username, COALESCE(u.team_name, ''),
+           COUNT(rv.user_id) FILTER (WHERE rv.state = 'ASSIGNED' AND pr.status IN ('OPEN', 'REOPENED')),
+           COUNT(rv.user_id)
+    FROM users u
+    LEFT JOIN pr_reviewers rv ON rv.user_id = u.user_id
+         AND ($1::timestamp IS NULL OR rv.assigned_at >= $1)
+         AND ($2::timestamp IS NULL OR rv.assigned_at < $2)
+    LEFT JOIN pull_requests pr ON pr.pull_request_id = rv.pull_request_id
+    WHERE ($3 = '' OR u.team_name = $3)
+    GROUP BY u.user_id, u.username, u.team_name
+    ORDER BY COUNT(rv.user_id) DESC, u.user_id
+  `
+
+	rows, err := r.q.Query(query, filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.UserStats{}
+	for rows.Next() {
+		var s models.UserStats
+		if err := rows.Scan(&s.UserID, &s.Username, &s.TeamName, &s.OpenAssignments, &s.TotalAssignments); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetTeamStats — PR по команде автора (окно по created_at) и назначения
+// на участников команды (окно по assigned_at)
+func (r *Repository) GetTeamStats(filter models.StatsFilter) ([]models.TeamStats, error) {
+	query := `
+    WITH prs AS (
+      SELECT u.team_name,
+             COUNT(*) AS total,
+             COUNT(*) FILTER (WHERE pr.status IN ('OPEN', 'REOPENED')) AS open,
+             COUNT(*) FILTER (WHERE pr.status = 'MERGED') AS merged
+      FROM pull_requests pr
+      JOIN users u ON u.user_id = pr.author_id
+      WHERE ($1::timestamp IS NULL OR pr.created_at >= $1)
+        AND ($2::timestamp IS NULL OR pr.created_at < $2)
+      GROUP BY u.team_name
+    ), assignments AS (
+      SELECT u.team_name,
+             COUNT(*) AS total,
+             COUNT(*) FILTER (WHERE rv.state = 'ASSIGNED' AND pr.status IN ('OPEN', 'REOPENED')) AS open
+      FROM pr_reviewers rv
+      JOIN users u ON u.user_id = rv.user_id
+      JOIN pull_requests pr ON pr.pull_request_id = rv.pull_request_id
+      WHERE ($1::timestamp IS NULL OR rv.assigned_at >= $1)
+        AND ($2::timestamp IS NULL OR rv.assigned_at < $2)
+      GROUP BY u.team_name
+    )
+    SELECT t.team_name,
+           COALESCE(prs.total, 0), COALESCE(prs.open, 0), COALESCE(prs.merged, 0),
+           COALESCE(a.open, 0), COALESCE(a.total, 0)
+    FROM teams t
+    LEFT JOIN prs ON prs.team_name = t.team_name
+    LEFT JOIN assignments a ON a.team_name = t.team_name
+    WHERE ($3 = '' OR t.team_name = $3)
+    ORDER BY t.team_name
+  `
+
+	rows, err := r.q.Query(query, filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.TeamStats{}
+	for rows.Next() {
+		var s models.TeamStats
+		err := rows.Scan(&s.TeamName, &s.PRsTotal, &s.PRsOpen, &s.PRsMerged, &s.OpenAssignments, &s.TotalAssignments)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetMergeTimeStats — среднее время до мерджа по командам авторов; окно по merged_at
+func (r *Repository) GetMergeTimeStats(filter models.StatsFilter) ([]models.MergeTimeStats, error) {
+	query := `
+    SELECT COALESCE(u.team_name, ''), COUNT(*),
+           AVG(EXTRACT(EPOCH FROM (pr.merged_at - pr.created_at)))
+    FROM pull_requests pr
+    JOIN users u ON u.user_id = pr.author_id
+    WHERE pr.status = 'MERGED' AND pr.merged_at IS NOT NULL
+      AND ($1::timestamp IS NULL OR pr.merged_at >= $1)
+      AND ($2::timestamp IS NULL OR pr.merged_at < $2)
+      AND ($3 = '' OR u.team_name = $3)
+    GROUP BY u.team_name
+    ORDER BY u.team_name
+  `
+
+	rows, err := r.q.Query(query, filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.MergeTimeStats{}
+	for rows.Next() {
+		var s models.MergeTimeStats
+		var avg sql.NullFloat64
+		if err := rows.Scan(&s.TeamName, &s.MergedPRs, &avg); err != nil {
+			return nil, err
+		}
+		s.AvgMergeSeconds = avg.Float64
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetReassignmentStats — переназначения из журнала событий; окно по времени события,
+// команда — текущая команда пользователя
+func (r *Repository) GetReassignmentStats(filter models.StatsFilter) ([]models.ReassignmentStats, error) {
+	query := `
+    WITH moves AS (
+      SELECT previous_user_id AS user_id, 1 AS reassigned_from, 0 AS reassigned_to, created_at
+      FROM pr_events WHERE event_type = 'REVIEWER_REASSIGNED'
+      UNION ALL
+      SELECT user_id, 0, 1, created_at
+      FROM pr_events WHERE event_type = 'REVIEWER_REASSIGNED'
+    )
+    SELECT m.user_id, COALESCE(u.team_name, ''), SUM(m.reassigned_from), SUM(m.reassigned_to)
+    FROM moves m
+    LEFT JOIN users u ON u.user_id = m.user_id
+    WHERE m.user_id IS NOT NULL
+      AND ($1::timestamp IS NULL OR m.created_at >= $1)
+      AND ($2::timestamp IS NULL OR m.created_at < $2)
+      AND ($3 = '' OR u.team_name = $3)
+    GROUP BY m.user_id, u.team_name
+    ORDER BY SUM(m.reassigned_from) DESC, m.user_id
+  `
+
+	rows, err := r.q.Query(query, filter.From, filter.To, filter.TeamName)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []models.ReassignmentStats{}
+	for rows.Next() {
+		var s models.ReassignmentStats
+		if err := rows.Scan(&s.UserID, &s.TeamName, &s.ReassignedFrom, &s.ReassignedTo); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
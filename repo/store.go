@@ -0,0 +1,107 @@
+package repo
+
+import (
+	"reviewtask/models"
+	"time"
+)
+
+// Store — хранилище команд, пользователей и PR, с которым работает сервис.
+// Repository хранит данные в PostgreSQL, MemoryStore — в памяти (для тестов);
+// семантика методов и возвращаемые ошибки у них совпадают.
+type Store interface {
+	// WithTx выполняет fn атомарно: изменения через tx фиксируются,
+	// только если fn вернула nil. Вложенный вызов выполняется в той же транзакции.
+	WithTx(fn func(tx Store) error) error
+
+	// Команды
+	CreateTeam(team *models.Team) error
+	GetTeam(teamName string) (*models.Team, error)
+	SetTeamPartners(teamName string, partnerTeams []string) error
+	UpdateTeamStrategy(teamName string, strategy models.ReviewerStrategy, params models.StrategyParams) error
+	GetTeamSettings(teamName string) (*models.TeamSettings, error)
+	UpsertTeamSettings(settings *models.TeamSettings) error
+
+	// Пользователи
+	GetUser(userID string) (*models.User, error)
+	CreateUser(user *models.User) error
+	UpdateUser(user *models.User) error
+	SetUserActive(userID string, isActive bool) error
+	GetActiveUsersByTeam(teamName string, excludeUserIDs []string) ([]models.User, error)
+	GetActiveUsersOutsideTeam(teamName string, excludeUserIDs []string) ([]models.User, error)
+
+	// Pull request'ы и ревьюеры
+	CreatePR(pr *models.PullRequest) error
+	GetPR(pullRequestID string) (*models.PullRequest, error)
+	GetPRForUpdate(pullRequestID string) (*models.PullRequest, error)
+	MergePR(pullRequestID string) error
+	UpdatePRStatus(pullRequestID string, status models.PRStatus) error
+	UpdatePRReviewers(pr *models.PullRequest) error
+	SubmitReview(pullRequestID, userID string, verdict models.ReviewVerdict, comment string) error
+	ListPRs(filter models.PRFilter) (*models.PRPage, error)
+	GetOpenPRIDsByReviewer(userID string) ([]string, error)
+	GetOpenReviewCounts(userIDs []string) (map[string]int, error)
+	GetLastAssignedAt(userIDs []string) (map[string]time.Time, error)
+
+	// Журнал событий
+	AddEvent(event *models.PREvent) error
+	GetPREvents(pullRequestID string) ([]models.PREvent, error)
+
+	// Outbox. AddOutboxEvent вызывается в транзакции изменения; событие
+	// с уже записанным ключом идемпотентности пропускается.
+	AddOutboxEvent(event *models.OutboxEvent) error
+	OutboxQueue
+
+	// Статистика: окно filter.From/To применяется ко времени назначения,
+	// создания PR, мерджа и переназначения соответственно
+	GetUserStats(filter models.StatsFilter) ([]models.UserStats, error)
+	GetTeamStats(filter models.StatsFilter) ([]models.TeamStats, error)
+	GetMergeTimeStats(filter models.StatsFilter) ([]models.MergeTimeStats, error)
+	GetReassignmentStats(filter models.StatsFilter) ([]models.ReassignmentStats, error)
+
+	// Отсутствия
+	CreateAbsence(absence *models.Absence) error
+	GetAbsence(absenceID int64) (*models.Absence, error)
+	GetUserAbsences(userID string, includePast bool) ([]models.Absence, error)
+	CancelAbsence(absenceID int64) error
+
+	// Внешние учётные записи. Пара provider + externalID, уже привязанная
+	// к пользователю, не добавляется повторно — возвращается ErrIdentityTaken.
+	AddUserIdentity(identity *models.UserIdentity) error
+	GetUserIdentity(provider, externalID string) (*models.UserIdentity, error)
+	ListUserIdentities(userID string) ([]models.UserIdentity, error)
+	DeleteUserIdentity(provider, externalID string) error
+
+	// Вебхуки. ListWebhookSubscriptions с пустым teamName возвращает все подписки,
+	// иначе — подписки команды и глобальные.
+	CreateWebhookSubscription(sub *models.WebhookSubscription) error
+	GetWebhookSubscription(subscriptionID int64) (*models.WebhookSubscription, error)
+	ListWebhookSubscriptions(teamName string) ([]models.WebhookSubscription, error)
+	DeleteWebhookSubscription(subscriptionID int64) error
+	EnqueueWebhookDelivery(delivery *models.WebhookDelivery) error
+	ListWebhookDeadLetters(subscriptionID int64) ([]models.WebhookDeadLetter, error)
+	RequeueWebhookDeadLetter(deadLetterID int64) (*models.WebhookDelivery, error)
+	WebhookQueue
+}
+
+// OutboxQueue — события outbox, ожидающие публикации. ClaimOutboxEvents
+// откладывает выданные события на lease, как ClaimWebhookDeliveries.
+type OutboxQueue interface {
+	ClaimOutboxEvents(now time.Time, limit int, lease time.Duration) ([]models.OutboxEvent, error)
+	CompleteOutboxEvent(outboxID int64) error
+	RetryOutboxEvent(outboxID int64, nextAttemptAt time.Time, lastError string) error
+}
+
+// WebhookQueue — очередь доставок вебхуков, которую разбирает фоновый обработчик.
+// ClaimWebhookDeliveries выдаёт до limit доставок, срок которых наступил к now,
+// и откладывает их на lease, чтобы параллельный обработчик не отправил их повторно.
+type WebhookQueue interface {
+	ClaimWebhookDeliveries(now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error)
+	CompleteWebhookDelivery(deliveryID int64) error
+	RetryWebhookDelivery(deliveryID int64, nextAttemptAt time.Time, lastError string) error
+	DeadLetterWebhookDelivery(deliveryID int64, lastError string) error
+}
+
+var (
+	_ Store = (*Repository)(nil)
+	_ Store = (*MemoryStore)(nil)
+)
@@ -0,0 +1,250 @@
+package repo
+
+import (
+	"database/sql"
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"sort"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+func (r *Repository) CreateWebhookSubscription(sub *models.WebhookSubscription) error {
+	return r.q.QueryRow(
+		`INSERT INTO webhook_subscriptions (team_name, url, secret, event_types)
+     VALUES ($1, $2, $3, $4)
+     RETURNING id, created_at`,
+		nullString(sub.TeamName), sub.URL, sub.Secret, pq.Array(eventTypeStrings(sub.EventTypes)),
+	).Scan(&sub.ID, &sub.CreatedAt)
+}
+
+func (r *Repository) GetWebhookSubscription(subscriptionID int64) (*models.WebhookSubscription, error) {
+	rows, err := r.q.Query(
+		`SELECT id, team_name, url, secret, event_types, created_at
+     FROM webhook_subscriptions WHERE id = $1`,
+		subscriptionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := scanWebhookSubscriptions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(subs) == 0 {
+		return nil, apperr.ErrWebhookNotFound
+	}
+	return &subs[0], nil
+}
+
+func (r *Repository) ListWebhookSubscriptions(teamName string) ([]models.WebhookSubscription, error) {
+	rows, err := r.q.Query(
+		`SELECT id, team_name, url, secret, event_types, created_at
+     FROM webhook_subscriptions
+     WHERE $1 = '' OR team_name IS NULL OR team_name = $1
+     ORDER BY id`,
+		teamName,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanWebhookSubscriptions(rows)
+}
+
+func (r *Repository) DeleteWebhookSubscription(subscriptionID int64) error {
+	result, err := r.q.Exec("DELETE FROM webhook_subscriptions WHERE id = $1", subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return apperr.ErrWebhookNotFound
+	}
+	return err
+}
+
+// EnqueueWebhookDelivery ставит доставку в очередь. Если доставка события
+// подписчику уже ждёт в очереди, вторая не создаётся.
+func (r *Repository) EnqueueWebhookDelivery(delivery *models.WebhookDelivery) error {
+	err := r.q.QueryRow(
+		`INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+     VALUES ($1, $2, $3, $4)
+     ON CONFLICT (subscription_id, event_id) DO NOTHING
+     RETURNING id, attempts, next_attempt_at, created_at`,
+		delivery.SubscriptionID, delivery.EventID, delivery.EventType, []byte(delivery.Payload),
+	).Scan(&delivery.ID, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	return err
+}
+
+// ClaimWebhookDeliveries выбирает доставки с SKIP LOCKED, поэтому несколько
+// экземпляров сервиса разбирают очередь, не мешая друг другу
+func (r *Repository) ClaimWebhookDeliveries(now time.Time, limit int, lease time.Duration) ([]models.WebhookDelivery, error) {
+	rows, err := r.q.Query(
+		`UPDATE webhook_deliveries d
+     SET next_attempt_at = $2
+     FROM webhook_subscriptions s
+     WHERE s.id = d.subscription_id AND d.id IN (
+       SELECT id FROM webhook_deliveries
+       WHERE next_attempt_at <= $1
+       ORDER BY next_attempt_at, id
+       LIMIT $3
+       FOR UPDATE SKIP LOCKED
+     )
+     RETURNING d.id, d.subscription_id, d.event_id, d.event_type, d.payload, d.attempts,
+       d.next_attempt_at, d.last_error, d.created_at, s.url, s.secret`,
+		now, now.Add(lease), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var delivery models.WebhookDelivery
+		var lastError sql.NullString
+		err := rows.Scan(
+			&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType,
+			&delivery.Payload, &delivery.Attempts, &delivery.NextAttemptAt, &lastError,
+			&delivery.CreatedAt, &delivery.URL, &delivery.Secret,
+		)
+		if err != nil {
+			return nil, err
+		}
+		delivery.LastError = lastError.String
+		deliveries = append(deliveries, delivery)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING не сохраняет порядок подзапроса
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	return deliveries, nil
+}
+
+func (r *Repository) CompleteWebhookDelivery(deliveryID int64) error {
+	_, err := r.q.Exec("DELETE FROM webhook_deliveries WHERE id = $1", deliveryID)
+	return err
+}
+
+func (r *Repository) RetryWebhookDelivery(deliveryID int64, nextAttemptAt time.Time, lastError string) error {
+	_, err := r.q.Exec(
+		`UPDATE webhook_deliveries
+     SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+     WHERE id = $1`,
+		deliveryID, nextAttemptAt, nullString(lastError),
+	)
+	return err
+}
+
+// DeadLetterWebhookDelivery переносит доставку в webhook_dead_letters одним запросом
+func (r *Repository) DeadLetterWebhookDelivery(deliveryID int64, lastError string) error {
+	_, err := r.q.Exec(
+		`WITH moved AS (
+       DELETE FROM webhook_deliveries WHERE id = $1
+       RETURNING subscription_id, event_id, event_type, payload, attempts, created_at
+     )
+     INSERT INTO webhook_dead_letters (subscription_id, event_id, event_type, payload, attempts, last_error, created_at)
+     SELECT subscription_id, event_id, event_type, payload, attempts + 1, $2, created_at FROM moved`,
+		deliveryID, nullString(lastError),
+	)
+	return err
+}
+
+func (r *Repository) ListWebhookDeadLetters(subscriptionID int64) ([]models.WebhookDeadLetter, error) {
+	rows, err := r.q.Query(
+		`SELECT id, subscription_id, event_id, event_type, payload, attempts, last_error, created_at, failed_at
+     FROM webhook_dead_letters
+     WHERE $1 = 0 OR subscription_id = $1
+     ORDER BY id`,
+		subscriptionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	letters := []models.WebhookDeadLetter{}
+	for rows.Next() {
+		var letter models.WebhookDeadLetter
+		var lastError sql.NullString
+		err := rows.Scan(
+			&letter.ID, &letter.SubscriptionID, &letter.EventID, &letter.EventType, &letter.Payload,
+			&letter.Attempts, &lastError, &letter.CreatedAt, &letter.FailedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		letter.LastError = lastError.String
+		letters = append(letters, letter)
+	}
+
+	return letters, rows.Err()
+}
+
+// RequeueWebhookDeadLetter возвращает доставку в очередь с обнулённым счётчиком попыток
+func (r *Repository) RequeueWebhookDeadLetter(deadLetterID int64) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	var lastError sql.NullString
+	err := r.q.QueryRow(
+		`WITH moved AS (
+       DELETE FROM webhook_dead_letters WHERE id = $1
+       RETURNING subscription_id, event_id, event_type, payload
+     )
+     INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload)
+     SELECT subscription_id, event_id, event_type, payload FROM moved
+     RETURNING id, subscription_id, event_id, event_type, payload, attempts, next_attempt_at, last_error, created_at`,
+		deadLetterID,
+	).Scan(
+		&delivery.ID, &delivery.SubscriptionID, &delivery.EventID, &delivery.EventType,
+		&delivery.Payload, &delivery.Attempts, &delivery.NextAttemptAt, &lastError, &delivery.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, apperr.ErrDeadLetterNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	delivery.LastError = lastError.String
+	return &delivery, nil
+}
+
+func scanWebhookSubscriptions(rows *sql.Rows) ([]models.WebhookSubscription, error) {
+	defer rows.Close()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		var sub models.WebhookSubscription
+		var teamName sql.NullString
+		var eventTypes []string
+		err := rows.Scan(&sub.ID, &teamName, &sub.URL, &sub.Secret, pq.Array(&eventTypes), &sub.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		sub.TeamName = teamName.String
+		sub.EventTypes = []models.EventType{}
+		for _, t := range eventTypes {
+			sub.EventTypes = append(sub.EventTypes, models.EventType(t))
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func eventTypeStrings(types []models.EventType) []string {
+	values := make([]string, 0, len(types))
+	for _, t := range types {
+		values = append(values, string(t))
+	}
+	return values
+}
@@ -6,6 +6,8 @@ echo "Используем существующие тестовые данны
 echo
 
 BASE_URL="http://localhost:8080"
+# Ключ из AUTH_API_KEYS; при AUTH_DISABLED=true не нужен
+API_KEY="${API_KEY:-}"
 TIMESTAMP=$(date +%s)
 
 PR_ID="pr-example-${TIMESTAMP}"
@@ -17,12 +19,12 @@ echo
 
 echo "1. Получаем информацию о команде backend"
 echo "-----------------------------------------"
-curl -s "$BASE_URL/team/get?team_name=backend" | jq '.'
+curl -s -H "X-API-Key: $API_KEY" "$BASE_URL/team/get?team_name=backend" | jq '.'
 echo
 
 echo "2. Создаем Pull Request (автоназначение ревьюеров)"
 echo "--------------------------------------------------"
-curl -X POST "$BASE_URL/pullRequest/create" \
+curl -X POST -H "X-API-Key: $API_KEY" "$BASE_URL/pullRequest/create" \
   -H "Content-Type: application/json" \
   -d '{
     "pull_request_id": "'"$PR_ID"'",
@@ -36,14 +38,14 @@ sleep 1
 
 echo "3. Смотрим PR пользователя u2"
 echo "-----------------------------"
-curl -s "$BASE_URL/users/getReview?user_id=u2" | jq '.'
+curl -s -H "X-API-Key: $API_KEY" "$BASE_URL/users/getReview?user_id=u2" | jq '.'
 echo
 
 sleep 1
 
 echo "4. Переназначаем ревьюера"
 echo "--------------------------"
-curl -X POST "$BASE_URL/pullRequest/reassign" \
+curl -X POST -H "X-API-Key: $API_KEY" "$BASE_URL/pullRequest/reassign" \
   -H "Content-Type: application/json" \
   -d '{
     "pull_request_id": "'"$PR_ID"'", 
@@ -56,14 +58,14 @@ sleep 1
 
 echo "5. Смотрим обновленный PR"
 echo "--------------------------"
-curl -s "$BASE_URL/users/getReview?user_id=u3" | jq '.'
+curl -s -H "X-API-Key: $API_KEY" "$BASE_URL/users/getReview?user_id=u3" | jq '.'
 echo
 
 sleep 1
 
 echo "6. Мерджим PR"
 echo "-------------"
-curl -X POST "$BASE_URL/pullRequest/merge" \
+curl -X POST -H "X-API-Key: $API_KEY" "$BASE_URL/pullRequest/merge" \
   -H "Content-Type: application/json" \
   -d '{
     "pull_request_id": "'"$PR_ID"'"
@@ -75,7 +77,7 @@ sleep 1
 
 echo "7. Пытаемся изменить мерджнутый PR (должна быть ошибка)"
 echo "-------------------------------------------------------"
-curl -X POST "$BASE_URL/pullRequest/reassign" \
+curl -X POST -H "X-API-Key: $API_KEY" "$BASE_URL/pullRequest/reassign" \
   -H "Content-Type: application/json" \
   -d '{
     "pull_request_id": "'"$PR_ID"'",
@@ -88,7 +90,7 @@ sleep 1
 
 echo "8. Деактивируем пользователя"
 echo "----------------------------"
-curl -X POST "$BASE_URL/users/setIsActive" \
+curl -X POST -H "X-API-Key: $API_KEY" "$BASE_URL/users/setIsActive" \
   -H "Content-Type: application/json" \
   -d '{
     "user_id": "u4",
@@ -101,7 +103,7 @@ sleep 1
 
 echo "9. Проверяем обновленного пользователя"
 echo "--------------------------------------"
-curl -s "$BASE_URL/team/get?team_name=backend" | jq '.members[] | select(.user_id == "u4")'
+curl -s -H "X-API-Key: $API_KEY" "$BASE_URL/team/get?team_name=backend" | jq '.members[] | select(.user_id == "u4")'
 echo
 
 echo "Примеры завершены!"
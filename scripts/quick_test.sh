@@ -8,17 +8,19 @@ echo "- Команда: frontend (u5, u6, u7)"
 echo
 
 BASE_URL="http://localhost:8080"
+# Ключ из AUTH_API_KEYS; при AUTH_DISABLED=true не нужен
+API_KEY="${API_KEY:-}"
 
 echo "1. Health check..."
 curl -s "$BASE_URL/health" | jq '.' || echo "Health check failed"
 echo
 
 echo "2. Получаем команду backend..."
-curl -s "$BASE_URL/team/get?team_name=backend" | jq '.' || echo "Failed to get team"
+curl -s -H "X-API-Key: $API_KEY" "$BASE_URL/team/get?team_name=backend" | jq '.' || echo "Failed to get team"
 echo
 
 echo "3. Создаем PR (автор u1 из команды backend)..."
-curl -s -X POST "$BASE_URL/pullRequest/create" \
+curl -s -X POST -H "X-API-Key: $API_KEY" "$BASE_URL/pullRequest/create" \
   -H "Content-Type: application/json" \
   -d '{
     "pull_request_id": "pr-quick-001",
@@ -28,11 +30,11 @@ curl -s -X POST "$BASE_URL/pullRequest/create" \
 echo
 
 echo "4. PR пользователя u2..."
-curl -s "$BASE_URL/users/getReview?user_id=u2" | jq '.' || echo "Failed to get user PRs"
+curl -s -H "X-API-Key: $API_KEY" "$BASE_URL/users/getReview?user_id=u2" | jq '.' || echo "Failed to get user PRs"
 echo
 
 echo "5. Мерджим PR..."
-curl -s -X POST "$BASE_URL/pullRequest/merge" \
+curl -s -X POST -H "X-API-Key: $API_KEY" "$BASE_URL/pullRequest/merge" \
   -H "Content-Type: application/json" \
   -d '{
     "pull_request_id": "pr-quick-001"
@@ -40,7 +42,7 @@ curl -s -X POST "$BASE_URL/pullRequest/merge" \
 echo
 
 echo "6. Пытаемся изменить мерджнутый PR..."
-curl -s -X POST "$BASE_URL/pullRequest/reassign" \
+curl -s -X POST -H "X-API-Key: $API_KEY" "$BASE_URL/pullRequest/reassign" \
   -H "Content-Type: application/json" \
   -d '{
     "pull_request_id": "pr-quick-001",
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// AddAbsence планирует отсутствие пользователя. Пока оно длится,
+// пользователь не выбирается ревьюером. Границы хранятся в UTC.
+func (s *ReviewService) AddAbsence(absence *models.Absence) (*models.Absence, error) {
+	absence.StartsAt = absence.StartsAt.UTC()
+	absence.EndsAt = absence.EndsAt.UTC()
+	if absence.StartsAt.IsZero() || !absence.EndsAt.After(absence.StartsAt) {
+		return nil, apperr.New(apperr.CodeBadRequest, "endsAt must be after startsAt")
+	}
+
+	if _, err := s.repo.GetUser(absence.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateAbsence(absence); err != nil {
+		return nil, fmt.Errorf("failed to create absence: %w", err)
+	}
+
+	return absence, nil
+}
+
+func (s *ReviewService) GetUserAbsences(userID string, includePast bool) ([]models.Absence, error) {
+	if _, err := s.repo.GetUser(userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetUserAbsences(userID, includePast)
+}
+
+func (s *ReviewService) GetAbsence(absenceID int64) (*models.Absence, error) {
+	return s.repo.GetAbsence(absenceID)
+}
+
+// CancelAbsence отменяет отсутствие; повторная отмена ничего не меняет
+func (s *ReviewService) CancelAbsence(absenceID int64) (*models.Absence, error) {
+	if _, err := s.repo.GetAbsence(absenceID); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CancelAbsence(absenceID); err != nil {
+		return nil, fmt.Errorf("failed to cancel absence: %w", err)
+	}
+
+	return s.repo.GetAbsence(absenceID)
+}
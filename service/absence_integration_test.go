@@ -0,0 +1,34 @@
+//go:build integration
+
+package service
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"reviewtask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbsenceKeepsClientOffset(t *testing.T) {
+	svc, db := newIntegrationService(t)
+	_, author := createIntegrationTeam(t, svc, db, 2)
+
+	absent := "conc-" + strings.TrimPrefix(author, "conc-author-") + "-0"
+
+	// Отсутствие началось час назад по часам клиента в UTC+3
+	startsAt := time.Now().Add(-time.Hour).In(time.FixedZone("MSK", 3*60*60)).Truncate(time.Microsecond)
+	added, err := svc.AddAbsence(&models.Absence{UserID: absent, StartsAt: startsAt, EndsAt: startsAt.Add(2 * time.Hour)})
+	require.NoError(t, err)
+
+	stored, err := svc.GetAbsence(added.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.StartsAt.Equal(startsAt), "stored %s, sent %s", stored.StartsAt, startsAt)
+
+	pr, err := svc.CreatePRWithReviewers("pr-"+author, "absence offset", author, false)
+	require.NoError(t, err)
+	assert.NotContains(t, pr.AssignedReviewers, absent)
+}
@@ -0,0 +1,77 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"reviewtask/apperr"
+	"reviewtask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryAbsenceLifecycle(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3")
+
+	// Клиент присылает границы со своим смещением; хранится тот же момент в UTC
+	moscow := time.FixedZone("MSK", 3*60*60)
+	startsAt := time.Now().Add(-time.Hour).In(moscow)
+	endsAt := startsAt.Add(2 * time.Hour)
+
+	added, err := svc.AddAbsence(&models.Absence{UserID: "u2", StartsAt: startsAt, EndsAt: endsAt, Reason: "vacation"})
+	require.NoError(t, err)
+	assert.True(t, added.StartsAt.Equal(startsAt))
+	assert.Equal(t, time.UTC, added.StartsAt.Location())
+	assert.Equal(t, time.UTC, added.EndsAt.Location())
+
+	absences, err := svc.GetUserAbsences("u2", false)
+	require.NoError(t, err)
+	require.Len(t, absences, 1)
+	assert.Equal(t, added.ID, absences[0].ID)
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3"}, pr.AssignedReviewers)
+
+	cancelled, err := svc.CancelAbsence(added.ID)
+	require.NoError(t, err)
+	require.NotNil(t, cancelled.CancelledAt)
+
+	// Повторная отмена не меняет время отмены
+	again, err := svc.CancelAbsence(added.ID)
+	require.NoError(t, err)
+	assert.True(t, again.CancelledAt.Equal(*cancelled.CancelledAt))
+
+	absences, err = svc.GetUserAbsences("u2", false)
+	require.NoError(t, err)
+	assert.Empty(t, absences)
+
+	absences, err = svc.GetUserAbsences("u2", true)
+	require.NoError(t, err)
+	require.Len(t, absences, 1)
+	assert.NotNil(t, absences[0].CancelledAt)
+
+	pr, err = svc.CreatePRWithReviewers("pr-2", "Fix search", "u1", false)
+	require.NoError(t, err)
+	assert.Contains(t, pr.AssignedReviewers, "u2")
+}
+
+func TestMemoryAbsenceErrors(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1")
+
+	now := time.Now()
+	_, err := svc.AddAbsence(&models.Absence{UserID: "u1", StartsAt: now, EndsAt: now})
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""))
+
+	_, err = svc.AddAbsence(&models.Absence{UserID: "ghost", StartsAt: now, EndsAt: now.Add(time.Hour)})
+	assert.ErrorIs(t, err, apperr.ErrUserNotFound)
+
+	_, err = svc.GetUserAbsences("ghost", false)
+	assert.ErrorIs(t, err, apperr.ErrUserNotFound)
+
+	_, err = svc.CancelAbsence(42)
+	assert.ErrorIs(t, err, apperr.ErrAbsenceNotFound)
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reviewtask/models"
+	"reviewtask/repo"
+)
+
+// inTx выполняет fn в транзакции; все обращения к репозиторию через txs
+// фиксируются или откатываются вместе
+func (s *ReviewService) inTx(fn func(txs *ReviewService) error) error {
+	return s.repo.WithTx(func(tx repo.Store) error {
+		return fn(&ReviewService{repo: tx})
+	})
+}
+
+func (s *ReviewService) addEvent(event models.PREvent) error {
+	if err := s.repo.AddEvent(&event); err != nil {
+		return fmt.Errorf("failed to record event: %w", err)
+	}
+	return s.addOutboxEvent(event)
+}
+
+// addOutboxEvent записывает событие в outbox в той же транзакции, что и журнал:
+// событие будет опубликовано, только если изменение зафиксировано.
+// Команда события — команда автора PR или затронутого пользователя.
+func (s *ReviewService) addOutboxEvent(event models.PREvent) error {
+	envelope := models.EventEnvelope{
+		IdempotencyKey: models.EventIdempotencyKey(event.ID),
+		Event:          event,
+	}
+
+	userID := event.UserID
+	if event.PullRequestID != "" {
+		pr, err := s.repo.GetPR(event.PullRequestID)
+		if err != nil {
+			return err
+		}
+		envelope.PullRequest = pr
+		userID = pr.AuthorID
+	}
+	if userID != "" {
+		if user, err := s.repo.GetUser(userID); err == nil {
+			envelope.TeamName = user.TeamName
+		}
+	}
+
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	err = s.repo.AddOutboxEvent(&models.OutboxEvent{
+		IdempotencyKey: envelope.IdempotencyKey,
+		EventID:        event.ID,
+		EventType:      event.EventType,
+		TeamName:       envelope.TeamName,
+		Payload:        payload,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record outbox event: %w", err)
+	}
+	return nil
+}
+
+// recordAssignments записывает автоматическое назначение ревьюеров PR
+func (s *ReviewService) recordAssignments(pr *models.PullRequest) error {
+	for _, reviewerID := range pr.AssignedReviewers {
+		reason := "auto-assigned"
+		if containsString(pr.FallbackReviewers, reviewerID) {
+			reason = "auto-assigned from fallback team"
+		}
+
+		err := s.addEvent(models.PREvent{
+			PullRequestID: pr.PullRequestID,
+			EventType:     models.EventReviewerAssigned,
+			UserID:        reviewerID,
+			Reason:        reason,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPRHistory возвращает журнал событий PR в хронологическом порядке
+func (s *ReviewService) GetPRHistory(prID string) ([]models.PREvent, error) {
+	if _, err := s.repo.GetPR(prID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPREvents(prID)
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"reviewtask/repo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryPRHistory(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3", "u4")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	events, err := svc.GetPRHistory("pr-1")
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, models.EventPRCreated, events[0].EventType)
+	assert.Equal(t, "u1", events[0].ActorID)
+	for i, reviewerID := range pr.AssignedReviewers {
+		assert.Equal(t, models.EventReviewerAssigned, events[i+1].EventType)
+		assert.Equal(t, reviewerID, events[i+1].UserID)
+		assert.Equal(t, "auto-assigned", events[i+1].Reason)
+	}
+
+	oldReviewer := pr.AssignedReviewers[0]
+	newReviewer, err := svc.ReassignReviewer("pr-1", oldReviewer, "u1", "on vacation")
+	require.NoError(t, err)
+
+	_, err = svc.MergePR("pr-1", "u2")
+	require.NoError(t, err)
+	// Повторный мердж не пишет событие
+	_, err = svc.MergePR("pr-1", "u2")
+	require.NoError(t, err)
+
+	events, err = svc.GetPRHistory("pr-1")
+	require.NoError(t, err)
+	require.Len(t, events, 5)
+
+	reassigned := events[3]
+	assert.Equal(t, models.EventReviewerReassigned, reassigned.EventType)
+	assert.Equal(t, "u1", reassigned.ActorID)
+	assert.Equal(t, oldReviewer, reassigned.PreviousUserID)
+	assert.Equal(t, newReviewer, reassigned.UserID)
+	assert.Equal(t, "on vacation", reassigned.Reason)
+
+	merged := events[4]
+	assert.Equal(t, models.EventPRMerged, merged.EventType)
+	assert.Equal(t, "u2", merged.ActorID)
+
+	for i := 1; i < len(events); i++ {
+		assert.Greater(t, events[i].ID, events[i-1].ID)
+	}
+
+	_, err = svc.GetPRHistory("missing")
+	assert.ErrorIs(t, err, apperr.ErrPRNotFound)
+}
+
+// failingOutboxStore отклоняет запись в outbox — уже после записи в журнал
+type failingOutboxStore struct {
+	*repo.MemoryStore
+}
+
+var errOutboxDown = errors.New("outbox unavailable")
+
+func (s *failingOutboxStore) WithTx(fn func(tx repo.Store) error) error {
+	return s.MemoryStore.WithTx(func(tx repo.Store) error {
+		return fn(&failingOutboxStore{MemoryStore: tx.(*repo.MemoryStore)})
+	})
+}
+
+func (s *failingOutboxStore) AddOutboxEvent(*models.OutboxEvent) error {
+	return errOutboxDown
+}
+
+func TestMemoryFailedChangeLeavesNoEvents(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svc := NewReviewService(store)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	before := eventTypes(t, svc, "pr-1")
+
+	failing := NewReviewService(&failingOutboxStore{MemoryStore: store})
+
+	_, err = failing.CreatePRWithReviewers("pr-2", "Add search", "u1", false)
+	assert.ErrorIs(t, err, errOutboxDown)
+	_, err = svc.GetPR("pr-2")
+	assert.ErrorIs(t, err, apperr.ErrPRNotFound)
+
+	_, err = failing.MergePR("pr-1", "u2")
+	assert.ErrorIs(t, err, errOutboxDown)
+	_, err = failing.ClosePR("pr-1", "u1", "")
+	assert.ErrorIs(t, err, errOutboxDown)
+
+	// Изменение и событие откатываются вместе
+	assert.Equal(t, before, eventTypes(t, svc, "pr-1"))
+	stored, err := svc.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusOpen, stored.Status)
+	assert.ElementsMatch(t, pr.AssignedReviewers, stored.AssignedReviewers)
+
+	// Отклонённый мердж не пишет событие
+	settings := models.DefaultTeamSettings("backend")
+	settings.RequiredApprovals = 1
+	_, err = svc.UpdateTeamSettings(settingsPatch(settings))
+	require.NoError(t, err)
+	_, err = svc.MergePR("pr-1", "u2")
+	assert.ErrorIs(t, err, apperr.ErrMergeBlocked)
+	assert.Equal(t, before, eventTypes(t, svc, "pr-1"))
+}
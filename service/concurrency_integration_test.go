@@ -0,0 +1,169 @@
+//go:build integration
+
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"reviewtask/apperr"
+	"reviewtask/database"
+	"reviewtask/models"
+	"reviewtask/repo"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Интеграционные тесты работают с PostgreSQL; недостающие миграции применяются перед тестом:
+// TEST_DATABASE_URL=postgres://... go test -tags integration ./service
+func newIntegrationService(t *testing.T) (*ReviewService, *sql.DB) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	require.NoError(t, db.Ping())
+	t.Cleanup(func() { db.Close() })
+
+	migrator, err := database.NewMigrator(db)
+	require.NoError(t, err)
+	_, err = migrator.Up()
+	require.NoError(t, err)
+
+	return NewReviewService(repo.NewRepository(db)), db
+}
+
+// createIntegrationTeam создаёт команду из автора и reviewers активных участников
+func createIntegrationTeam(t *testing.T, svc *ReviewService, db *sql.DB, reviewers int) (string, string) {
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	team := &models.Team{TeamName: "conc-" + suffix}
+
+	author := "conc-author-" + suffix
+	team.Members = append(team.Members, models.TeamMember{UserID: author, Username: "author", IsActive: true})
+	for i := 0; i < reviewers; i++ {
+		team.Members = append(team.Members, models.TeamMember{
+			UserID:   fmt.Sprintf("conc-%s-%d", suffix, i),
+			Username: fmt.Sprintf("reviewer %d", i),
+			IsActive: true,
+		})
+	}
+	require.NoError(t, svc.CreateTeam(team))
+
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM pull_requests WHERE author_id = $1", author)
+		db.Exec("DELETE FROM pr_events WHERE user_id LIKE $1 OR actor_id LIKE $1", "conc-%"+suffix+"%")
+		db.Exec("DELETE FROM teams WHERE team_name = $1", team.TeamName)
+	})
+
+	return team.TeamName, author
+}
+
+func TestConcurrentReassignKeepsReviewersConsistent(t *testing.T) {
+	svc, db := newIntegrationService(t)
+	_, author := createIntegrationTeam(t, svc, db, 8)
+
+	prID := "pr-" + author
+	pr, err := svc.CreatePRWithReviewers(prID, "concurrent reassign", author, false)
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	const workers = 10
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		succeeded int
+	)
+
+	for i := 0; i < workers; i++ {
+		// Половина горутин заменяет первого ревьюера, половина — второго
+		oldUserID := pr.AssignedReviewers[i%2]
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := svc.ReassignReviewer(prID, oldUserID, author, "load test")
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+				return
+			}
+			assert.True(t,
+				errors.Is(err, apperr.ErrNotAssigned) || errors.Is(err, apperr.ErrNoCandidate),
+				"unexpected error: %v", err,
+			)
+		}()
+	}
+	wg.Wait()
+
+	// Каждый ревьюер заменяется не более одного раза: после первой замены он уже не назначен
+	assert.LessOrEqual(t, succeeded, 2)
+	assert.GreaterOrEqual(t, succeeded, 1)
+
+	final, err := svc.repo.GetPR(prID)
+	require.NoError(t, err)
+	require.Len(t, final.AssignedReviewers, 2)
+	assert.NotEqual(t, final.AssignedReviewers[0], final.AssignedReviewers[1])
+	assert.NotContains(t, final.AssignedReviewers, author)
+
+	var assigned int
+	require.NoError(t, db.QueryRow(
+		"SELECT COUNT(*) FROM pr_reviewers WHERE pull_request_id = $1 AND state = 'ASSIGNED'", prID,
+	).Scan(&assigned))
+	assert.Equal(t, 2, assigned)
+
+	var reassignEvents int
+	require.NoError(t, db.QueryRow(
+		"SELECT COUNT(*) FROM pr_events WHERE pull_request_id = $1 AND event_type = $2",
+		prID, models.EventReviewerReassigned,
+	).Scan(&reassignEvents))
+	assert.Equal(t, succeeded, reassignEvents)
+}
+
+func TestConcurrentCreatePRWithSameID(t *testing.T) {
+	svc, db := newIntegrationService(t)
+	_, author := createIntegrationTeam(t, svc, db, 3)
+
+	prID := "pr-" + author
+	const workers = 8
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created int
+	)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			_, err := svc.CreatePRWithReviewers(prID, "concurrent create", author, false)
+			if err == nil {
+				mu.Lock()
+				created++
+				mu.Unlock()
+				return
+			}
+			assert.ErrorIs(t, err, apperr.ErrPRExists)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, created)
+
+	var events int
+	require.NoError(t, db.QueryRow(
+		"SELECT COUNT(*) FROM pr_events WHERE pull_request_id = $1 AND event_type = $2",
+		prID, models.EventPRCreated,
+	).Scan(&events))
+	assert.Equal(t, 1, events)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// DeactivateUsers деактивирует пользователей из userIDs и всех участников команды teamName
+// и в той же транзакции переназначает их открытые ревью активным коллегам.
+// PR, для которых замену найти не удалось, перечисляются в отчёте с ошибкой.
+func (s *ReviewService) DeactivateUsers(userIDs []string, teamName, actorID, reason string) (*models.DeactivationReport, error) {
+	if len(userIDs) == 0 && teamName == "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "user_ids or team_name is required")
+	}
+
+	report := &models.DeactivationReport{
+		Deactivated:   []string{},
+		Reassignments: []models.ReassignmentResult{},
+	}
+
+	err := s.inTx(func(txs *ReviewService) error {
+		targets, err := txs.resolveDeactivationTargets(userIDs, teamName)
+		if err != nil {
+			return err
+		}
+
+		// Сначала деактивируем всех, чтобы они не стали заменой друг другу
+		for _, userID := range targets {
+			if _, err := txs.SetUserActive(userID, false, actorID, reason); err != nil {
+				return err
+			}
+			report.Deactivated = append(report.Deactivated, userID)
+		}
+
+		for _, userID := range targets {
+			results, err := txs.reassignOpenReviews(userID, actorID, reason)
+			if err != nil {
+				return err
+			}
+			report.Reassignments = append(report.Reassignments, results...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// resolveDeactivationTargets возвращает уникальный список пользователей для деактивации
+func (s *ReviewService) resolveDeactivationTargets(userIDs []string, teamName string) ([]string, error) {
+	var targets []string
+	seen := make(map[string]bool)
+
+	for _, userID := range userIDs {
+		if seen[userID] {
+			continue
+		}
+		if _, err := s.repo.GetUser(userID); err != nil {
+			return nil, err
+		}
+		seen[userID] = true
+		targets = append(targets, userID)
+	}
+
+	if teamName != "" {
+		team, err := s.repo.GetTeam(teamName)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, member := range team.Members {
+			if !seen[member.UserID] {
+				seen[member.UserID] = true
+				targets = append(targets, member.UserID)
+			}
+		}
+	}
+
+	return targets, nil
+}
+
+// reassignOpenReviews переназначает все открытые ревью пользователя.
+// PR, для которых замену найти не удалось, возвращаются с ошибкой и
+// остаются за пользователем.
+func (s *ReviewService) reassignOpenReviews(userID, actorID, reason string) ([]models.ReassignmentResult, error) {
+	prIDs, err := s.repo.GetOpenPRIDsByReviewer(userID)
+	if err != nil {
+		return nil, fmt.Errorf("get open reviews: %w", err)
+	}
+
+	results := []models.ReassignmentResult{}
+	for _, prID := range prIDs {
+		result := models.ReassignmentResult{PullRequestID: prID, OldUserID: userID}
+
+		newReviewer, err := s.reassignReviewer(prID, userID, actorID, reason)
+		switch {
+		case err == nil:
+			result.NewUserID = newReviewer
+			result.Reassigned = true
+		case errors.Is(err, apperr.ErrNoCandidate):
+			result.Error = err.Error()
+		default:
+			return nil, err
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"strings"
+)
+
+// identityProviderPattern — допустимое имя внешней системы: github, gitlab, email, slack...
+var identityProviderPattern = regexp.MustCompile(`^[a-z][a-z0-9_.-]{0,63}$`)
+
+// normalizeIdentity приводит provider и externalID к виду, в котором они хранятся:
+// без пробелов по краям и в нижнем регистре — логины и email сравниваются без учёта регистра
+func normalizeIdentity(provider, externalID string) (string, string, error) {
+	provider, externalID = models.NormalizeIdentity(provider, externalID)
+
+	if !identityProviderPattern.MatchString(provider) {
+		return "", "", apperr.New(apperr.CodeBadRequest, "provider must be lowercase letters, digits, '_', '-' or '.'")
+	}
+	if externalID == "" || len(externalID) > 255 {
+		return "", "", apperr.New(apperr.CodeBadRequest, "external_id is required and must be at most 255 characters")
+	}
+	if models.IsHostingIdentity(provider) && strings.HasSuffix(provider, models.IdentityIDSuffix) && !isDigits(externalID) {
+		return "", "", apperr.New(apperr.CodeBadRequest, "external_id of "+provider+" must be a numeric account ID")
+	}
+	return provider, externalID, nil
+}
+
+func isDigits(value string) bool {
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return value != ""
+}
+
+// AddUserIdentity привязывает внешнюю учётную запись к пользователю.
+// Повторная привязка к тому же пользователю ничего не меняет; привязанная
+// к другому пользователю запись возвращает IDENTITY_TAKEN.
+func (s *ReviewService) AddUserIdentity(identity *models.UserIdentity) (*models.UserIdentity, error) {
+	provider, externalID, err := normalizeIdentity(identity.Provider, identity.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	identity.Provider, identity.ExternalID = provider, externalID
+
+	var added *models.UserIdentity
+	err = s.inTx(func(txs *ReviewService) error {
+		if _, err := txs.repo.GetUser(identity.UserID); err != nil {
+			return err
+		}
+
+		existing, err := txs.repo.GetUserIdentity(provider, externalID)
+		switch {
+		case err == nil && existing.UserID == identity.UserID:
+			added = existing
+			return nil
+		case err == nil:
+			return apperr.ErrIdentityTaken
+		case !errors.Is(err, apperr.ErrIdentityNotFound):
+			return err
+		}
+
+		if err := txs.repo.AddUserIdentity(identity); err != nil {
+			if errors.Is(err, apperr.ErrIdentityTaken) {
+				return err
+			}
+			return fmt.Errorf("failed to add identity: %w", err)
+		}
+		added = identity
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return added, nil
+}
+
+func (s *ReviewService) GetUserIdentity(provider, externalID string) (*models.UserIdentity, error) {
+	provider, externalID, err := normalizeIdentity(provider, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetUserIdentity(provider, externalID)
+}
+
+func (s *ReviewService) ListUserIdentities(userID string) ([]models.UserIdentity, error) {
+	if _, err := s.repo.GetUser(userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListUserIdentities(userID)
+}
+
+// RemoveUserIdentity отвязывает внешнюю учётную запись и возвращает её
+func (s *ReviewService) RemoveUserIdentity(provider, externalID string) (*models.UserIdentity, error) {
+	provider, externalID, err := normalizeIdentity(provider, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	var removed *models.UserIdentity
+	err = s.inTx(func(txs *ReviewService) error {
+		removed, err = txs.repo.GetUserIdentity(provider, externalID)
+		if err != nil {
+			return err
+		}
+		return txs.repo.DeleteUserIdentity(provider, externalID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return removed, nil
+}
+
+// ResolveUser находит пользователя по любой из его внешних учётных записей
+func (s *ReviewService) ResolveUser(provider, externalID string) (*models.User, error) {
+	identity, err := s.GetUserIdentity(provider, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetUser(identity.UserID)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+
+	"reviewtask/apperr"
+	"reviewtask/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryUserIdentities(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	added, err := svc.AddUserIdentity(&models.UserIdentity{UserID: "u1", Provider: " GitHub ", ExternalID: "OctoCat"})
+	require.NoError(t, err)
+	assert.Equal(t, "github", added.Provider)
+	assert.Equal(t, "octocat", added.ExternalID)
+	assert.False(t, added.CreatedAt.IsZero())
+
+	_, err = svc.AddUserIdentity(&models.UserIdentity{UserID: "u1", Provider: "email", ExternalID: "u1@example.com"})
+	require.NoError(t, err)
+
+	// Повторная привязка к тому же пользователю ничего не меняет
+	again, err := svc.AddUserIdentity(&models.UserIdentity{UserID: "u1", Provider: "github", ExternalID: "octocat"})
+	require.NoError(t, err)
+	assert.Equal(t, added.CreatedAt, again.CreatedAt)
+
+	_, err = svc.AddUserIdentity(&models.UserIdentity{UserID: "u2", Provider: "github", ExternalID: "OCTOCAT"})
+	assert.ErrorIs(t, err, apperr.ErrIdentityTaken)
+
+	user, err := svc.ResolveUser("github", "Octocat")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", user.UserID)
+	assert.Equal(t, "backend", user.TeamName)
+
+	_, err = svc.ResolveUser("gitlab", "octocat")
+	assert.ErrorIs(t, err, apperr.ErrIdentityNotFound)
+
+	// Числовой ID — отдельное пространство: он не находится под провайдером логинов
+	_, err = svc.AddUserIdentity(&models.UserIdentity{UserID: "u2", Provider: "github-id", ExternalID: "583231"})
+	require.NoError(t, err)
+	_, err = svc.ResolveUser("github", "583231")
+	assert.ErrorIs(t, err, apperr.ErrIdentityNotFound)
+	user, err = svc.ResolveUser("github-id", "583231")
+	require.NoError(t, err)
+	assert.Equal(t, "u2", user.UserID)
+
+	identities, err := svc.ListUserIdentities("u1")
+	require.NoError(t, err)
+	require.Len(t, identities, 2)
+	assert.Equal(t, "email", identities[0].Provider)
+	assert.Equal(t, "github", identities[1].Provider)
+
+	removed, err := svc.RemoveUserIdentity("github", "octocat")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", removed.UserID)
+	_, err = svc.RemoveUserIdentity("github", "octocat")
+	assert.ErrorIs(t, err, apperr.ErrIdentityNotFound)
+
+	// После отвязки запись можно привязать к другому пользователю
+	_, err = svc.AddUserIdentity(&models.UserIdentity{UserID: "u2", Provider: "github", ExternalID: "octocat"})
+	require.NoError(t, err)
+}
+
+func TestMemoryUserIdentityValidation(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1")
+
+	invalid := []models.UserIdentity{
+		{UserID: "u1", Provider: "", ExternalID: "octocat"},
+		{UserID: "u1", Provider: "git hub", ExternalID: "octocat"},
+		{UserID: "u1", Provider: "github", ExternalID: "  "},
+		{UserID: "u1", Provider: "github-id", ExternalID: "octocat"},
+	}
+	for _, identity := range invalid {
+		_, err := svc.AddUserIdentity(&identity)
+		assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""), "%+v", identity)
+	}
+
+	_, err := svc.AddUserIdentity(&models.UserIdentity{UserID: "ghost", Provider: "github", ExternalID: "ghost"})
+	assert.ErrorIs(t, err, apperr.ErrUserNotFound)
+
+	_, err = svc.ListUserIdentities("ghost")
+	assert.ErrorIs(t, err, apperr.ErrUserNotFound)
+}
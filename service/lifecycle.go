@@ -0,0 +1,101 @@
+package service
+
+import (
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// prTransitions — допустимые переходы между статусами PR.
+// MERGED — конечное состояние.
+var prTransitions = map[models.PRStatus][]models.PRStatus{
+	models.StatusDraft:    {models.StatusOpen, models.StatusClosed},
+	models.StatusOpen:     {models.StatusMerged, models.StatusClosed},
+	models.StatusReopened: {models.StatusMerged, models.StatusClosed},
+	models.StatusClosed:   {models.StatusReopened},
+}
+
+func canTransition(from, to models.PRStatus) bool {
+	for _, status := range prTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+func isValidStatus(status models.PRStatus) bool {
+	_, ok := prTransitions[status]
+	return ok || status == models.StatusMerged
+}
+
+// transitionEvents — событие журнала для каждого целевого статуса
+var transitionEvents = map[models.PRStatus]models.EventType{
+	models.StatusOpen:     models.EventPRReady,
+	models.StatusClosed:   models.EventPRClosed,
+	models.StatusReopened: models.EventPRReopened,
+}
+
+// MarkReady переводит черновик в OPEN и назначает ревьюеров
+func (s *ReviewService) MarkReady(prID, actorID, reason string) (*models.PullRequest, error) {
+	return s.transitionPR(prID, models.StatusOpen, actorID, reason)
+}
+
+// ClosePR закрывает PR без мерджа
+func (s *ReviewService) ClosePR(prID, actorID, reason string) (*models.PullRequest, error) {
+	return s.transitionPR(prID, models.StatusClosed, actorID, reason)
+}
+
+// ReopenPR переоткрывает закрытый PR; если ревьюеров нет, они назначаются заново
+func (s *ReviewService) ReopenPR(prID, actorID, reason string) (*models.PullRequest, error) {
+	return s.transitionPR(prID, models.StatusReopened, actorID, reason)
+}
+
+func (s *ReviewService) transitionPR(prID string, to models.PRStatus, actorID, reason string) (*models.PullRequest, error) {
+	err := s.inTx(func(txs *ReviewService) error {
+		pr, err := txs.repo.GetPRForUpdate(prID)
+		if err != nil {
+			return err
+		}
+
+		if !canTransition(pr.Status, to) {
+			return apperr.ErrInvalidTransition
+		}
+
+		if err := txs.repo.UpdatePRStatus(prID, to); err != nil {
+			return fmt.Errorf("failed to update PR status: %w", err)
+		}
+
+		err = txs.addEvent(models.PREvent{
+			PullRequestID: prID,
+			EventType:     transitionEvents[to],
+			ActorID:       actorID,
+			Reason:        reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		if !to.IsOpen() || len(pr.AssignedReviewers) > 0 {
+			return nil
+		}
+
+		reviewers, fallback, err := txs.AssignReviewers(pr.AuthorID, pr.PullRequestName)
+		if err != nil {
+			return err
+		}
+
+		pr.AssignedReviewers = reviewers
+		pr.FallbackReviewers = fallback
+		if err := txs.repo.UpdatePRReviewers(pr); err != nil {
+			return fmt.Errorf("failed to update PR: %w", err)
+		}
+
+		return txs.recordAssignments(pr)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPR(prID)
+}
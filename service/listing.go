@@ -0,0 +1,105 @@
+package service
+
+import (
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// Размер страницы списков: по умолчанию и максимальный
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// GetTeam возвращает команду с участниками
+func (s *ReviewService) GetTeam(teamName string) (*models.Team, error) {
+	return s.repo.GetTeam(teamName)
+}
+
+// GetUser возвращает пользователя по идентификатору
+func (s *ReviewService) GetUser(userID string) (*models.User, error) {
+	return s.repo.GetUser(userID)
+}
+
+// GetPR возвращает PR с ревьюерами и вердиктами
+func (s *ReviewService) GetPR(prID string) (*models.PullRequest, error) {
+	return s.repo.GetPR(prID)
+}
+
+// ListPRs возвращает страницу PR под фильтром
+func (s *ReviewService) ListPRs(filter models.PRFilter) (*models.PRPage, error) {
+	if err := normalizePRFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListPRs(filter)
+}
+
+// GetUserReviewPRs возвращает страницу PR, на которые назначен пользователь
+func (s *ReviewService) GetUserReviewPRs(userID string, filter models.PRFilter) (*models.PRPage, error) {
+	filter.ReviewerID = userID
+	if err := normalizePRFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.GetUser(userID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListPRs(filter)
+}
+
+// normalizePRFilter проверяет фильтр, подставляет значения по умолчанию
+// и приводит границы окон и курсор к UTC
+func normalizePRFilter(filter *models.PRFilter) error {
+	switch filter.SortBy {
+	case "":
+		filter.SortBy = models.SortByCreatedAt
+	case models.SortByCreatedAt, models.SortByMergedAt:
+	default:
+		return apperr.New(apperr.CodeBadRequest, "sort must be created_at or merged_at")
+	}
+
+	switch filter.Order {
+	case "":
+		filter.Order = models.SortDesc
+	case models.SortAsc, models.SortDesc:
+	default:
+		return apperr.New(apperr.CodeBadRequest, "order must be asc or desc")
+	}
+
+	if filter.Cursor != nil && !filter.Cursor.Matches(filter.SortBy, filter.Order) {
+		return apperr.New(apperr.CodeBadRequest, "cursor was issued for a different sort or order")
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DefaultPageSize
+	}
+	if filter.Limit < 1 || filter.Limit > MaxPageSize {
+		return apperr.New(apperr.CodeBadRequest, fmt.Sprintf("limit must be between 1 and %d", MaxPageSize))
+	}
+
+	for _, status := range filter.Statuses {
+		if !isValidStatus(status) {
+			return apperr.New(apperr.CodeBadRequest, "unknown PR status "+string(status))
+		}
+	}
+
+	filter.CreatedFrom, filter.CreatedTo = utcTime(filter.CreatedFrom), utcTime(filter.CreatedTo)
+	filter.MergedFrom, filter.MergedTo = utcTime(filter.MergedFrom), utcTime(filter.MergedTo)
+	if filter.Cursor != nil {
+		cursor := *filter.Cursor
+		cursor.Time = cursor.Time.UTC()
+		filter.Cursor = &cursor
+	}
+
+	if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+		return apperr.New(apperr.CodeBadRequest, "created_from must be before created_to")
+	}
+	if filter.MergedFrom != nil && filter.MergedTo != nil && !filter.MergedFrom.Before(*filter.MergedTo) {
+		return apperr.New(apperr.CodeBadRequest, "merged_from must be before merged_to")
+	}
+
+	return nil
+}
@@ -0,0 +1,176 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// AddTeamMember добавляет пользователя в команду. Новый пользователь создаётся;
+// ранее исключённый из команды возвращается с новыми именем и активностью.
+// Пользователь другой команды не добавляется — для этого есть MoveUserToTeam.
+func (s *ReviewService) AddTeamMember(teamName string, member models.TeamMember, actorID, reason string) (*models.MembershipChange, error) {
+	if member.UserID == "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "user_id is required")
+	}
+
+	user := &models.User{
+		UserID:   member.UserID,
+		Username: member.Username,
+		TeamName: teamName,
+		IsActive: member.IsActive,
+	}
+
+	err := s.inTx(func(txs *ReviewService) error {
+		if err := txs.ensureTeamExists(teamName); err != nil {
+			return err
+		}
+
+		existing, err := txs.repo.GetUser(member.UserID)
+		switch {
+		case errors.Is(err, apperr.ErrUserNotFound):
+			if user.Username == "" {
+				return apperr.New(apperr.CodeBadRequest, "username is required")
+			}
+			if err := txs.repo.CreateUser(user); err != nil {
+				return fmt.Errorf("failed to create user: %w", err)
+			}
+		case err != nil:
+			return err
+		case existing.TeamName == teamName:
+			return apperr.New(apperr.CodeUserInTeam, "user already in team")
+		case existing.TeamName != "":
+			return apperr.New(apperr.CodeUserInTeam, "user belongs to another team")
+		default:
+			if user.Username == "" {
+				user.Username = existing.Username
+			}
+			if err := txs.repo.UpdateUser(user); err != nil {
+				return fmt.Errorf("failed to update user: %w", err)
+			}
+		}
+
+		return txs.addEvent(models.PREvent{
+			EventType: models.EventUserTeamChanged,
+			ActorID:   actorID,
+			UserID:    user.UserID,
+			Reason:    reason,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MembershipChange{User: user, Reassignments: []models.ReassignmentResult{}}, nil
+}
+
+// RemoveTeamMember исключает пользователя из команды и деактивирует его.
+// Его открытые ревью переназначаются, как при деактивации; авторство PR сохраняется.
+func (s *ReviewService) RemoveTeamMember(teamName, userID, actorID, reason string) (*models.MembershipChange, error) {
+	var change *models.MembershipChange
+
+	err := s.inTx(func(txs *ReviewService) error {
+		if err := txs.ensureTeamExists(teamName); err != nil {
+			return err
+		}
+
+		user, err := txs.repo.GetUser(userID)
+		if err != nil {
+			return err
+		}
+		if user.TeamName != teamName {
+			return apperr.New(apperr.CodeNotFound, "user is not a team member")
+		}
+
+		change, err = txs.changeUserTeam(user, "", true, actorID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// MoveUserToTeam переводит пользователя в другую команду. Если keepReviews
+// не задан, открытые ревью пользователя до перевода переназначаются;
+// замена выбирается из команды автора PR.
+func (s *ReviewService) MoveUserToTeam(userID, teamName string, keepReviews bool, actorID, reason string) (*models.MembershipChange, error) {
+	var change *models.MembershipChange
+
+	err := s.inTx(func(txs *ReviewService) error {
+		user, err := txs.repo.GetUser(userID)
+		if err != nil {
+			return err
+		}
+
+		if err := txs.ensureTeamExists(teamName); err != nil {
+			return err
+		}
+		if user.TeamName == teamName {
+			return apperr.New(apperr.CodeUserInTeam, "user already in team")
+		}
+
+		change, err = txs.changeUserTeam(user, teamName, !keepReviews, actorID, reason)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+// changeUserTeam переносит пользователя в teamName (пустое имя — исключение
+// из команды с деактивацией). Открытые ревью переназначаются до смены команды,
+// замена выбирается из команды автора PR, как при ручном переназначении.
+func (s *ReviewService) changeUserTeam(user *models.User, teamName string, reassign bool, actorID, reason string) (*models.MembershipChange, error) {
+	change := &models.MembershipChange{
+		User:             user,
+		PreviousTeamName: user.TeamName,
+		Reassignments:    []models.ReassignmentResult{},
+	}
+
+	// Исключённый деактивируется так же, как через SetUserActive: с событием
+	// USER_DEACTIVATED и до переназначения, чтобы не стать заменой самому себе
+	if teamName == "" && user.IsActive {
+		if _, err := s.SetUserActive(user.UserID, false, actorID, reason); err != nil {
+			return nil, err
+		}
+		user.IsActive = false
+	}
+
+	if reassign {
+		results, err := s.reassignOpenReviews(user.UserID, actorID, reason)
+		if err != nil {
+			return nil, err
+		}
+		change.Reassignments = results
+	}
+
+	user.TeamName = teamName
+
+	if err := s.repo.UpdateUser(user); err != nil {
+		return nil, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	err := s.addEvent(models.PREvent{
+		EventType: models.EventUserTeamChanged,
+		ActorID:   actorID,
+		UserID:    user.UserID,
+		Reason:    reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return change, nil
+}
+
+func (s *ReviewService) ensureTeamExists(teamName string) error {
+	if _, err := s.repo.GetTeam(teamName); err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"reviewtask/repo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// userOutboxEvents — типы ожидающих публикации событий пользователя userID, не связанных с PR
+func userOutboxEvents(t *testing.T, store *repo.MemoryStore, userID string) []models.EventType {
+	t.Helper()
+	events, err := store.ClaimOutboxEvents(time.Now().Add(time.Hour), 1000, time.Hour)
+	require.NoError(t, err)
+
+	types := []models.EventType{}
+	for _, event := range events {
+		var envelope models.EventEnvelope
+		require.NoError(t, json.Unmarshal(event.Payload, &envelope))
+		if envelope.Event.UserID == userID && envelope.Event.PullRequestID == "" {
+			types = append(types, event.EventType)
+		}
+	}
+	return types
+}
+
+func TestMemoryAddTeamMemberRejectsExistingMembers(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+	createMemoryTeam(t, svc, "frontend", "f1")
+
+	_, err := svc.AddTeamMember("backend", models.TeamMember{UserID: "u1", Username: "u1", IsActive: true}, "lead", "")
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeUserInTeam, ""))
+
+	_, err = svc.AddTeamMember("backend", models.TeamMember{UserID: "f1", Username: "f1", IsActive: true}, "lead", "")
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeUserInTeam, ""))
+
+	user, err := svc.GetUser("f1")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", user.TeamName)
+}
+
+func TestMemoryAddTeamMemberReturnsRemovedUser(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+	createMemoryTeam(t, svc, "frontend", "f1")
+
+	_, err := svc.RemoveTeamMember("backend", "u2", "lead", "")
+	require.NoError(t, err)
+
+	change, err := svc.AddTeamMember("frontend", models.TeamMember{UserID: "u2", IsActive: true}, "lead", "")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", change.User.TeamName)
+	assert.Equal(t, "u2", change.User.Username)
+	assert.True(t, change.User.IsActive)
+}
+
+func TestMemoryRemoveTeamMemberDeactivatesAndReassigns(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svc := NewReviewService(store)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3", "u4")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	removed := pr.AssignedReviewers[0]
+
+	_, err = svc.RemoveTeamMember("frontend", removed, "lead", "")
+	assert.ErrorIs(t, err, apperr.ErrTeamNotFound)
+
+	change, err := svc.RemoveTeamMember("backend", removed, "lead", "left company")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", change.PreviousTeamName)
+	assert.Empty(t, change.User.TeamName)
+	assert.False(t, change.User.IsActive)
+
+	require.Len(t, change.Reassignments, 1)
+	reassignment := change.Reassignments[0]
+	assert.True(t, reassignment.Reassigned)
+	assert.Equal(t, removed, reassignment.OldUserID)
+
+	pr, err = svc.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.NotContains(t, pr.AssignedReviewers, removed)
+	assert.Contains(t, pr.AssignedReviewers, reassignment.NewUserID)
+
+	assert.Equal(t, []models.EventType{
+		models.EventUserDeactivated,
+		models.EventUserTeamChanged,
+	}, userOutboxEvents(t, store, removed))
+
+	_, err = svc.RemoveTeamMember("backend", removed, "lead", "")
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeNotFound, ""))
+}
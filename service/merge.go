@@ -0,0 +1,82 @@
+package service
+
+import (
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// Правила мерджа, которые может включить команда автора PR
+const (
+	RuleRequiredApprovals  = "REQUIRED_APPROVALS"
+	RuleNoChangesRequested = "NO_CHANGES_REQUESTED"
+	RuleNoSelfMerge        = "NO_SELF_MERGE"
+	RuleHasReviewer        = "HAS_REVIEWER"
+)
+
+// MergeRuleViolation — нарушенное правило мерджа
+type MergeRuleViolation struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// mergeBlockedError возвращается MergePR, если не выполнены правила мерджа;
+// нарушенные правила попадают в ответ в поле failed_rules
+func mergeBlockedError(violations []MergeRuleViolation) error {
+	return apperr.ErrMergeBlocked.WithDetails("failed_rules", violations)
+}
+
+// evaluateMergeRules проверяет PR по включённым правилам команды и
+// возвращает все нарушения. actorID — пользователь, выполняющий мердж.
+func evaluateMergeRules(pr *models.PullRequest, settings *models.TeamSettings, actorID string) []MergeRuleViolation {
+	var violations []MergeRuleViolation
+
+	approvals := 0
+	changesRequested := 0
+	for _, review := range pr.Reviews {
+		switch review.Verdict {
+		case models.VerdictApproved:
+			approvals++
+		case models.VerdictChangesRequested:
+			changesRequested++
+		}
+	}
+
+	if settings.RequireReviewer && len(pr.AssignedReviewers) == 0 {
+		violations = append(violations, MergeRuleViolation{
+			Rule:    RuleHasReviewer,
+			Message: "PR must have at least one reviewer",
+		})
+	}
+
+	if approvals < settings.RequiredApprovals {
+		violations = append(violations, MergeRuleViolation{
+			Rule:    RuleRequiredApprovals,
+			Message: fmt.Sprintf("%d of %d required approvals", approvals, settings.RequiredApprovals),
+		})
+	}
+
+	if settings.BlockOnChangesRequested && changesRequested > 0 {
+		violations = append(violations, MergeRuleViolation{
+			Rule:    RuleNoChangesRequested,
+			Message: fmt.Sprintf("%d reviewer(s) requested changes", changesRequested),
+		})
+	}
+
+	if settings.DisallowSelfMerge {
+		switch actorID {
+		case "":
+			violations = append(violations, MergeRuleViolation{
+				Rule:    RuleNoSelfMerge,
+				Message: "merged_by is required to merge this PR",
+			})
+		case pr.AuthorID:
+			violations = append(violations, MergeRuleViolation{
+				Rule:    RuleNoSelfMerge,
+				Message: "author cannot merge own PR",
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func violatedRules(violations []MergeRuleViolation) []string {
+	rules := []string{}
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestEvaluateMergeRules(t *testing.T) {
+	pr := &models.PullRequest{
+		AuthorID:          "u1",
+		AssignedReviewers: []string{"u2", "u3"},
+		Reviews: []models.Review{
+			{UserID: "u2", Verdict: models.VerdictApproved},
+			{UserID: "u3", Verdict: models.VerdictChangesRequested},
+		},
+	}
+
+	t.Run("no rules by default", func(t *testing.T) {
+		assert.Empty(t, evaluateMergeRules(pr, models.DefaultTeamSettings("backend"), "u1"))
+	})
+
+	t.Run("required approvals", func(t *testing.T) {
+		settings := models.DefaultTeamSettings("backend")
+		settings.RequiredApprovals = 1
+		assert.Empty(t, evaluateMergeRules(pr, settings, "u2"))
+
+		settings.RequiredApprovals = 2
+		assert.Equal(t, []string{RuleRequiredApprovals}, violatedRules(evaluateMergeRules(pr, settings, "u2")))
+	})
+
+	t.Run("outstanding change requests", func(t *testing.T) {
+		settings := models.DefaultTeamSettings("backend")
+		settings.BlockOnChangesRequested = true
+		assert.Equal(t, []string{RuleNoChangesRequested}, violatedRules(evaluateMergeRules(pr, settings, "u2")))
+	})
+
+	t.Run("self merge", func(t *testing.T) {
+		settings := models.DefaultTeamSettings("backend")
+		settings.DisallowSelfMerge = true
+		assert.Equal(t, []string{RuleNoSelfMerge}, violatedRules(evaluateMergeRules(pr, settings, "u1")))
+		assert.Equal(t, []string{RuleNoSelfMerge}, violatedRules(evaluateMergeRules(pr, settings, "")))
+		assert.Empty(t, evaluateMergeRules(pr, settings, "u2"))
+	})
+
+	t.Run("PR without reviewers", func(t *testing.T) {
+		settings := models.DefaultTeamSettings("backend")
+		settings.RequireReviewer = true
+		empty := &models.PullRequest{AuthorID: "u1", AssignedReviewers: []string{}}
+		assert.Equal(t, []string{RuleHasReviewer}, violatedRules(evaluateMergeRules(empty, settings, "u2")))
+		assert.Empty(t, evaluateMergeRules(pr, settings, "u2"))
+	})
+
+	t.Run("all failed rules are reported", func(t *testing.T) {
+		settings := &models.TeamSettings{
+			RequiredApprovals:       1,
+			BlockOnChangesRequested: true,
+			RequireReviewer:         true,
+			DisallowSelfMerge:       true,
+		}
+		empty := &models.PullRequest{AuthorID: "u1", AssignedReviewers: []string{}}
+		assert.Equal(t,
+			[]string{RuleHasReviewer, RuleRequiredApprovals, RuleNoSelfMerge},
+			violatedRules(evaluateMergeRules(empty, settings, "u1")),
+		)
+	})
+}
+
+func TestMergeBlockedError(t *testing.T) {
+	violations := []MergeRuleViolation{{Rule: RuleHasReviewer}}
+	err := mergeBlockedError(violations)
+	assert.EqualError(t, err, "merge requirements not met")
+	assert.ErrorIs(t, err, apperr.ErrMergeBlocked)
+
+	appErr, ok := apperr.From(err)
+	require.True(t, ok)
+	assert.Equal(t, apperr.CodeMergeBlocked, appErr.Code)
+	assert.Equal(t, violations, appErr.Details["failed_rules"])
+}
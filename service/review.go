@@ -0,0 +1,54 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// SubmitReview сохраняет вердикт ревьюера по открытому PR
+func (s *ReviewService) SubmitReview(prID, reviewerID string, verdict models.ReviewVerdict, comment string) (*models.PullRequest, error) {
+	if !isValidVerdict(verdict) {
+		return nil, apperr.New(apperr.CodeBadRequest, "verdict must be APPROVED, CHANGES_REQUESTED or COMMENTED")
+	}
+
+	err := s.inTx(func(txs *ReviewService) error {
+		pr, err := txs.repo.GetPRForUpdate(prID)
+		if err != nil {
+			return err
+		}
+
+		if !pr.Status.IsOpen() {
+			return apperr.New(apperr.CodeInvalidTransition, "reviews can only be submitted on open PR")
+		}
+
+		if err := txs.repo.SubmitReview(prID, reviewerID, verdict, comment); err != nil {
+			if errors.Is(err, apperr.ErrNotAssigned) {
+				return err
+			}
+			return fmt.Errorf("failed to submit review: %w", err)
+		}
+
+		return txs.addEvent(models.PREvent{
+			PullRequestID: prID,
+			EventType:     models.EventReviewSubmitted,
+			ActorID:       reviewerID,
+			UserID:        reviewerID,
+			Reason:        string(verdict),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetPR(prID)
+}
+
+func isValidVerdict(verdict models.ReviewVerdict) bool {
+	switch verdict {
+	case models.VerdictApproved, models.VerdictChangesRequested, models.VerdictCommented:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SelectionRequest описывает одну выборку ревьюеров
+type SelectionRequest struct {
+	TeamName   string
+	PRName     string
+	Candidates []models.User
+	Count      int
+}
+
+// ReviewerSelector выбирает ревьюеров среди кандидатов
+type ReviewerSelector interface {
+	Select(req SelectionRequest) ([]string, error)
+}
+
+// ReviewHistory — данные о прошлых назначениях, нужные стратегиям
+type ReviewHistory interface {
+	GetOpenReviewCounts(userIDs []string) (map[string]int, error)
+	GetLastAssignedAt(userIDs []string) (map[string]time.Time, error)
+}
+
+// NewReviewerSelector создаёт селектор для стратегии команды
+func NewReviewerSelector(strategy models.ReviewerStrategy, params models.StrategyParams, history ReviewHistory) (ReviewerSelector, error) {
+	switch strategy {
+	case "", models.StrategyRandom:
+		return &randomSelector{shuffle: rand.Shuffle}, nil
+	case models.StrategyRoundRobin:
+		return &roundRobinSelector{history: history}, nil
+	case models.StrategyLeastLoaded:
+		return &leastLoadedSelector{history: history, shuffle: rand.Shuffle}, nil
+	case models.StrategyWeighted:
+		return &weightedSelector{weights: params.Weights, random: rand.Float64}, nil
+	case models.StrategyExpertise:
+		return &expertiseSelector{expertise: params.Expertise, shuffle: rand.Shuffle}, nil
+	default:
+		return nil, apperr.New(apperr.CodeBadRequest, "unknown reviewer strategy")
+	}
+}
+
+// validateStrategy проверяет стратегию и её параметры до сохранения
+func validateStrategy(strategy models.ReviewerStrategy, params models.StrategyParams) error {
+	if _, err := NewReviewerSelector(strategy, params, nil); err != nil {
+		return err
+	}
+
+	for _, weight := range params.Weights {
+		if weight <= 0 {
+			return apperr.New(apperr.CodeBadRequest, "invalid strategy params")
+		}
+	}
+
+	return nil
+}
+
+type shuffleFunc func(n int, swap func(i, j int))
+
+// randomSelector — случайный выбор
+type randomSelector struct {
+	shuffle shuffleFunc
+}
+
+func (s *randomSelector) Select(req SelectionRequest) ([]string, error) {
+	shuffled := shuffleUsers(req.Candidates, s.shuffle)
+	return firstIDs(shuffled, req.Count), nil
+}
+
+// roundRobinSelector выбирает тех, кого дольше всего не назначали;
+// никогда не назначавшиеся идут первыми, при равенстве — по user_id
+type roundRobinSelector struct {
+	history ReviewHistory
+}
+
+func (s *roundRobinSelector) Select(req SelectionRequest) ([]string, error) {
+	lastAssigned, err := s.history.GetLastAssignedAt(userIDs(req.Candidates))
+	if err != nil {
+		return nil, fmt.Errorf("get assignment history: %w", err)
+	}
+
+	ordered := make([]models.User, len(req.Candidates))
+	copy(ordered, req.Candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		ti, tj := lastAssigned[ordered[i].UserID], lastAssigned[ordered[j].UserID]
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return ordered[i].UserID < ordered[j].UserID
+	})
+
+	return firstIDs(ordered, req.Count), nil
+}
+
+// leastLoadedSelector предпочитает участников с наименьшим числом открытых ревью
+type leastLoadedSelector struct {
+	history ReviewHistory
+	shuffle shuffleFunc
+}
+
+func (s *leastLoadedSelector) Select(req SelectionRequest) ([]string, error) {
+	load, err := s.history.GetOpenReviewCounts(userIDs(req.Candidates))
+	if err != nil {
+		return nil, fmt.Errorf("get review load: %w", err)
+	}
+
+	return pickLeastLoaded(req.Candidates, load, req.Count, s.shuffle), nil
+}
+
+// pickLeastLoaded выбирает count кандидатов с наименьшим числом открытых ревью.
+// Порядок кандидатов с одинаковой нагрузкой задаётся shuffle.
+func pickLeastLoaded(users []models.User, load map[string]int, count int, shuffle shuffleFunc) []string {
+	shuffled := shuffleUsers(users, shuffle)
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return load[shuffled[i].UserID] < load[shuffled[j].UserID]
+	})
+
+	return firstIDs(shuffled, count)
+}
+
+// weightedSelector — случайный выбор без повторений с вероятностью,
+// пропорциональной весу участника (алгоритм Efraimidis–Spirakis)
+type weightedSelector struct {
+	weights map[string]int
+	random  func() float64
+}
+
+func (s *weightedSelector) Select(req SelectionRequest) ([]string, error) {
+	keys := make(map[string]float64, len(req.Candidates))
+	for _, user := range req.Candidates {
+		weight, ok := s.weights[user.UserID]
+		if !ok {
+			weight = 1
+		}
+		keys[user.UserID] = math.Pow(s.random(), 1/float64(weight))
+	}
+
+	ordered := make([]models.User, len(req.Candidates))
+	copy(ordered, req.Candidates)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return keys[ordered[i].UserID] > keys[ordered[j].UserID]
+	})
+
+	return firstIDs(ordered, req.Count), nil
+}
+
+// expertiseSelector предпочитает участников, чьи ключевые слова встречаются
+// в названии PR; при равном совпадении — случайно
+type expertiseSelector struct {
+	expertise map[string][]string
+	shuffle   shuffleFunc
+}
+
+func (s *expertiseSelector) Select(req SelectionRequest) ([]string, error) {
+	prName := strings.ToLower(req.PRName)
+
+	scores := make(map[string]int, len(req.Candidates))
+	for _, user := range req.Candidates {
+		for _, keyword := range s.expertise[user.UserID] {
+			if keyword != "" && strings.Contains(prName, strings.ToLower(keyword)) {
+				scores[user.UserID]++
+			}
+		}
+	}
+
+	shuffled := shuffleUsers(req.Candidates, s.shuffle)
+	sort.SliceStable(shuffled, func(i, j int) bool {
+		return scores[shuffled[i].UserID] > scores[shuffled[j].UserID]
+	})
+
+	return firstIDs(shuffled, req.Count), nil
+}
+
+func shuffleUsers(users []models.User, shuffle shuffleFunc) []models.User {
+	shuffled := make([]models.User, len(users))
+	copy(shuffled, users)
+	shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+	return shuffled
+}
+
+func firstIDs(users []models.User, count int) []string {
+	if count > len(users) {
+		count = len(users)
+	}
+	if count <= 0 {
+		return []string{}
+	}
+
+	result := make([]string, count)
+	for i := 0; i < count; i++ {
+		result[i] = users[i].UserID
+	}
+	return result
+}
+
+func userIDs(users []models.User) []string {
+	ids := make([]string, len(users))
+	for i, user := range users {
+		ids[i] = user.UserID
+	}
+	return ids
+}
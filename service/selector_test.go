@@ -0,0 +1,174 @@
+package service
+
+import (
+	"math/rand"
+	"reviewtask/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHistory struct {
+	load         map[string]int
+	lastAssigned map[string]time.Time
+}
+
+func (h *fakeHistory) GetOpenReviewCounts(userIDs []string) (map[string]int, error) {
+	return h.load, nil
+}
+
+func (h *fakeHistory) GetLastAssignedAt(userIDs []string) (map[string]time.Time, error) {
+	return h.lastAssigned, nil
+}
+
+func noShuffle(n int, swap func(i, j int)) {}
+
+func reverseShuffle(n int, swap func(i, j int)) {
+	for i := 0; i < n/2; i++ {
+		swap(i, n-1-i)
+	}
+}
+
+var candidates = []models.User{
+	{UserID: "u1"}, {UserID: "u2"}, {UserID: "u3"}, {UserID: "u4"},
+}
+
+func TestNewReviewerSelector(t *testing.T) {
+	strategies := []models.ReviewerStrategy{
+		"",
+		models.StrategyRandom,
+		models.StrategyRoundRobin,
+		models.StrategyLeastLoaded,
+		models.StrategyWeighted,
+		models.StrategyExpertise,
+	}
+	for _, strategy := range strategies {
+		selector, err := NewReviewerSelector(strategy, models.StrategyParams{}, &fakeHistory{})
+		require.NoError(t, err, strategy)
+		assert.NotNil(t, selector)
+	}
+
+	_, err := NewReviewerSelector("fastest", models.StrategyParams{}, nil)
+	assert.EqualError(t, err, "unknown reviewer strategy")
+}
+
+func TestValidateStrategy(t *testing.T) {
+	assert.NoError(t, validateStrategy(models.StrategyWeighted, models.StrategyParams{
+		Weights: map[string]int{"u1": 3},
+	}))
+	assert.EqualError(t, validateStrategy(models.StrategyWeighted, models.StrategyParams{
+		Weights: map[string]int{"u1": 0},
+	}), "invalid strategy params")
+	assert.EqualError(t, validateStrategy("fastest", models.StrategyParams{}), "unknown reviewer strategy")
+}
+
+func TestRandomSelector(t *testing.T) {
+	selector := &randomSelector{shuffle: reverseShuffle}
+	result, err := selector.Select(SelectionRequest{Candidates: candidates, Count: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u4", "u3"}, result)
+
+	result, err = selector.Select(SelectionRequest{Candidates: candidates[:1], Count: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u1"}, result)
+
+	result, err = selector.Select(SelectionRequest{Count: 2})
+	require.NoError(t, err)
+	assert.Empty(t, result)
+}
+
+func TestRoundRobinSelector(t *testing.T) {
+	now := time.Now()
+	history := &fakeHistory{lastAssigned: map[string]time.Time{
+		"u1": now.Add(-time.Hour),
+		"u2": now,
+		"u4": now.Add(-2 * time.Hour),
+	}}
+
+	selector := &roundRobinSelector{history: history}
+	result, err := selector.Select(SelectionRequest{Candidates: candidates, Count: 3})
+	require.NoError(t, err)
+	// u3 ни разу не назначался, дальше — по давности последнего назначения
+	assert.Equal(t, []string{"u3", "u4", "u1"}, result)
+}
+
+func TestLeastLoadedSelector(t *testing.T) {
+	history := &fakeHistory{load: map[string]int{"u1": 5, "u2": 0, "u3": 2, "u4": 1}}
+	selector := &leastLoadedSelector{history: history, shuffle: noShuffle}
+
+	result, err := selector.Select(SelectionRequest{Candidates: candidates, Count: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u4"}, result)
+}
+
+func TestPickLeastLoaded(t *testing.T) {
+	t.Run("users without reviews count as zero load", func(t *testing.T) {
+		load := map[string]int{"u1": 1, "u2": 1, "u3": 1}
+		assert.Equal(t, []string{"u4"}, pickLeastLoaded(candidates, load, 1, noShuffle))
+	})
+
+	t.Run("ties are broken by shuffle", func(t *testing.T) {
+		load := map[string]int{"u1": 3}
+		assert.Equal(t, []string{"u4", "u3"}, pickLeastLoaded(candidates, load, 2, reverseShuffle))
+	})
+
+	t.Run("seeded shuffle is deterministic", func(t *testing.T) {
+		first := pickLeastLoaded(candidates, nil, 2, rand.New(rand.NewSource(42)).Shuffle)
+		second := pickLeastLoaded(candidates, nil, 2, rand.New(rand.NewSource(42)).Shuffle)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("no candidates", func(t *testing.T) {
+		assert.Empty(t, pickLeastLoaded(nil, nil, 2, noShuffle))
+	})
+}
+
+func TestWeightedSelector(t *testing.T) {
+	t.Run("heavier weight wins for equal random draw", func(t *testing.T) {
+		selector := &weightedSelector{
+			weights: map[string]int{"u3": 10},
+			random:  func() float64 { return 0.5 },
+		}
+		result, err := selector.Select(SelectionRequest{Candidates: candidates, Count: 1})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"u3"}, result)
+	})
+
+	t.Run("distribution follows weights", func(t *testing.T) {
+		rnd := rand.New(rand.NewSource(7))
+		selector := &weightedSelector{
+			weights: map[string]int{"u1": 9},
+			random:  rnd.Float64,
+		}
+
+		picks := map[string]int{}
+		for i := 0; i < 1000; i++ {
+			result, err := selector.Select(SelectionRequest{Candidates: candidates[:2], Count: 1})
+			require.NoError(t, err)
+			picks[result[0]]++
+		}
+		assert.Greater(t, picks["u1"], 800)
+		assert.Greater(t, picks["u2"], 0)
+	})
+}
+
+func TestExpertiseSelector(t *testing.T) {
+	selector := &expertiseSelector{
+		expertise: map[string][]string{
+			"u1": {"frontend"},
+			"u2": {"Payments", "db"},
+			"u4": {"payments"},
+		},
+		shuffle: noShuffle,
+	}
+
+	result, err := selector.Select(SelectionRequest{
+		PRName:     "Fix payments DB migration",
+		Candidates: candidates,
+		Count:      3,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2", "u4", "u1"}, result)
+}
@@ -1,122 +1,301 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"reviewtask/apperr"
 	"reviewtask/models"
 	"reviewtask/repo"
+	"time"
 )
 
 type ReviewService struct {
-	repo *repo.Repository
+	repo repo.Store
 }
 
-func NewReviewService(repo *repo.Repository) *ReviewService {
-	return &ReviewService{repo: repo}
+func NewReviewService(store repo.Store) *ReviewService {
+	return &ReviewService{repo: store}
 }
 
-func (s *ReviewService) AssignReviewers(authorID string) ([]string, error) {
+// AssignReviewers выбирает ревьюеров для нового PR автора. Вторым значением
+// возвращаются ревьюеры, добранные не из команды автора.
+func (s *ReviewService) AssignReviewers(authorID, prName string) ([]string, []string, error) {
 	author, err := s.repo.GetUser(authorID)
 	if err != nil {
-		return nil, fmt.Errorf("author not found: %w", err)
+		return nil, nil, fmt.Errorf("author not found: %w", err)
+	}
+
+	settings, err := s.repo.GetTeamSettings(author.TeamName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get team settings: %w", err)
 	}
 
 	excludeIDs := []string{authorID}
 
-	availableUsers, err := s.repo.GetActiveUsersByTeam(author.TeamName, excludeIDs)
+	reviewers, fallback, err := s.pickReviewers(author.TeamName, prName, settings, excludeIDs, settings.MaxReviewers)
 	if err != nil {
-		return nil, fmt.Errorf("get available reviewers: %w", err)
+		return nil, nil, err
 	}
 
-	reviewers := s.repo.GetRandomReviewers(availableUsers, 2)
+	if len(reviewers) < settings.MinReviewers && settings.FailOnInsufficient {
+		return nil, nil, apperr.ErrNotEnoughReviewers
+	}
 
-	return reviewers, nil
+	return reviewers, fallback, nil
 }
 
-func (s *ReviewService) CreatePRWithReviewers(prID, prName, authorID string) (*models.PullRequest, error) {
-	existingPR, _ := s.repo.GetPR(prID)
-	if existingPR != nil {
-		return nil, fmt.Errorf("PR id already exists")
+// pickReviewers выбирает до count ревьюеров среди активных участников команды.
+// Если их не хватает, добирает из команд-партнёров в порядке приоритета,
+// а при allow_cross_team — из всех остальных команд.
+func (s *ReviewService) pickReviewers(teamName, prName string, settings *models.TeamSettings, excludeIDs []string, count int) ([]string, []string, error) {
+	team, err := s.repo.GetTeam(teamName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get team: %w", err)
 	}
 
-	_, err := s.repo.GetUser(authorID)
+	availableUsers, err := s.repo.GetActiveUsersByTeam(teamName, excludeIDs)
 	if err != nil {
-		return nil, fmt.Errorf("author not found")
+		return nil, nil, fmt.Errorf("get available reviewers: %w", err)
 	}
 
-	reviewers, err := s.AssignReviewers(authorID)
+	reviewers, err := s.selectReviewers(team, prName, availableUsers, count)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
+	var fallback []string
+	excluded := append(append([]string{}, excludeIDs...), reviewers...)
+
+	for _, partner := range team.PartnerTeams {
+		if len(reviewers) >= count {
+			break
+		}
+
+		partnerUsers, err := s.repo.GetActiveUsersByTeam(partner, excluded)
+		if err != nil {
+			return nil, nil, fmt.Errorf("get partner team reviewers: %w", err)
+		}
+
+		extra, err := s.selectReviewers(team, prName, partnerUsers, count-len(reviewers))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		reviewers = append(reviewers, extra...)
+		fallback = append(fallback, extra...)
+		excluded = append(excluded, extra...)
+	}
+
+	if len(reviewers) >= count || !settings.AllowCrossTeam {
+		return reviewers, fallback, nil
+	}
+
+	otherUsers, err := s.repo.GetActiveUsersOutsideTeam(teamName, excluded)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get cross-team reviewers: %w", err)
+	}
+
+	extra, err := s.selectReviewers(team, prName, otherUsers, count-len(reviewers))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(reviewers, extra...), append(fallback, extra...), nil
+}
+
+// CreatePRWithReviewers создаёт PR и назначает ревьюеров.
+// Черновик (draft) создаётся без ревьюеров — они назначаются в MarkReady.
+func (s *ReviewService) CreatePRWithReviewers(prID, prName, authorID string, draft bool) (*models.PullRequest, error) {
 	pr := &models.PullRequest{
 		PullRequestID:     prID,
 		PullRequestName:   prName,
 		AuthorID:          authorID,
-		Status:            models.StatusOpen,
-		AssignedReviewers: reviewers,
+		Status:            models.StatusDraft,
+		AssignedReviewers: []string{},
 	}
 
-	if err := s.repo.CreatePR(pr); err != nil {
-		return nil, fmt.Errorf("failed to create PR: %w", err)
+	err := s.inTx(func(txs *ReviewService) error {
+		// Быстрая проверка до выбора ревьюеров; при параллельном создании
+		// дубликат отсекает INSERT ... ON CONFLICT в CreatePR
+		existingPR, _ := txs.repo.GetPR(prID)
+		if existingPR != nil {
+			return apperr.ErrPRExists
+		}
+
+		author, err := txs.repo.GetUser(authorID)
+		if err != nil || author.TeamName == "" {
+			return apperr.ErrAuthorNotFound
+		}
+
+		if !draft {
+			reviewers, fallback, err := txs.AssignReviewers(authorID, prName)
+			if err != nil {
+				return err
+			}
+
+			pr.Status = models.StatusOpen
+			pr.AssignedReviewers = reviewers
+			pr.FallbackReviewers = fallback
+		}
+
+		if err := txs.repo.CreatePR(pr); err != nil {
+			return fmt.Errorf("failed to create PR: %w", err)
+		}
+
+		err = txs.addEvent(models.PREvent{
+			PullRequestID: prID,
+			EventType:     models.EventPRCreated,
+			ActorID:       authorID,
+		})
+		if err != nil {
+			return err
+		}
+
+		return txs.recordAssignments(pr)
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return pr, nil
 }
 
-func (s *ReviewService) MergePR(prID string) (*models.PullRequest, error) {
-	pr, err := s.repo.GetPR(prID)
+// MergePR мерджит PR от имени actorID. Повторный мердж ничего не меняет.
+// Если не выполнены правила мерджа команды автора, возвращается ошибка MERGE_BLOCKED
+// со списком нарушенных правил.
+func (s *ReviewService) MergePR(prID, actorID string) (*models.PullRequest, error) {
+	return s.mergePR(prID, actorID, "", true)
+}
+
+// RecordMerge фиксирует мердж, уже выполненный вне сервиса (на Git-хостинге).
+// Правила мерджа и допустимые переходы статусов не проверяются: отклонить
+// свершившийся мердж нельзя. reason попадает в журнал событий.
+func (s *ReviewService) RecordMerge(prID, actorID, reason string) (*models.PullRequest, error) {
+	return s.mergePR(prID, actorID, reason, false)
+}
+
+func (s *ReviewService) mergePR(prID, actorID, reason string, enforceRules bool) (*models.PullRequest, error) {
+	var merged *models.PullRequest
+
+	err := s.inTx(func(txs *ReviewService) error {
+		pr, err := txs.repo.GetPRForUpdate(prID)
+		if err != nil {
+			return err
+		}
+
+		if pr.Status == models.StatusMerged {
+			merged = pr
+			return nil
+		}
+
+		if enforceRules {
+			if err := txs.checkMergeRules(pr, actorID); err != nil {
+				return err
+			}
+		}
+
+		if err := txs.repo.MergePR(prID); err != nil {
+			return fmt.Errorf("failed to merge PR: %w", err)
+		}
+
+		return txs.addEvent(models.PREvent{
+			PullRequestID: prID,
+			EventType:     models.EventPRMerged,
+			ActorID:       actorID,
+			Reason:        reason,
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("PR not found")
+		return nil, err
 	}
 
-	if pr.Status == models.StatusMerged {
-		return pr, nil
+	if merged != nil {
+		return merged, nil
 	}
+	return s.repo.GetPR(prID)
+}
 
-	if err := s.repo.MergePR(prID); err != nil {
-		return nil, fmt.Errorf("failed to merge PR: %w", err)
+// checkMergeRules проверяет, что PR можно смерджить в сервисе: переход статуса
+// допустим и правила мерджа команды автора выполнены
+func (s *ReviewService) checkMergeRules(pr *models.PullRequest, actorID string) error {
+	if !canTransition(pr.Status, models.StatusMerged) {
+		return apperr.New(apperr.CodeInvalidTransition, "PR cannot be merged in its current state")
 	}
 
-	return s.repo.GetPR(prID)
+	author, err := s.repo.GetUser(pr.AuthorID)
+	if err != nil {
+		return fmt.Errorf("author not found: %w", err)
+	}
+
+	settings, err := s.repo.GetTeamSettings(author.TeamName)
+	if err != nil {
+		return fmt.Errorf("get team settings: %w", err)
+	}
+
+	if violations := evaluateMergeRules(pr, settings, actorID); len(violations) > 0 {
+		return mergeBlockedError(violations)
+	}
+	return nil
 }
 
-func (s *ReviewService) GetUserReviewPRs(userID string) ([]models.PullRequestShort, error) {
-	if _, err := s.repo.GetUser(userID); err != nil {
-		return nil, fmt.Errorf("user not found")
+// ReassignReviewer заменяет ревьюера oldUserID; actorID и reason попадают в журнал событий
+func (s *ReviewService) ReassignReviewer(pullRequestID, oldUserID, actorID, reason string) (string, error) {
+	var newReviewer string
+	err := s.inTx(func(txs *ReviewService) error {
+		var err error
+		newReviewer, err = txs.reassignReviewer(pullRequestID, oldUserID, actorID, reason)
+		return err
+	})
+	if err != nil {
+		return "", err
 	}
 
-	return s.repo.GetPRsByReviewer(userID)
+	return newReviewer, nil
 }
 
-func (s *ReviewService) ReassignReviewer(pullRequestID string, oldUserID string) (string, error) {
-	pr, err := s.repo.GetPR(pullRequestID)
+// reassignReviewer выполняется в транзакции вызывающего: строка PR блокируется,
+// поэтому параллельные переназначения одного PR не затирают друг друга
+func (s *ReviewService) reassignReviewer(pullRequestID, oldUserID, actorID, reason string) (string, error) {
+	pr, err := s.repo.GetPRForUpdate(pullRequestID)
 	if err != nil {
-		return "", fmt.Errorf("PR not found: %w", err)
+		return "", err
 	}
 
 	if pr.Status == models.StatusMerged {
-		return "", fmt.Errorf("cannot reassign on merged PR")
+		return "", apperr.ErrPRMerged
+	}
+
+	if !pr.Status.IsOpen() {
+		return "", apperr.New(apperr.CodeInvalidTransition, "reviewers can only be reassigned on open PR")
 	}
 
 	if !containsString(pr.AssignedReviewers, oldUserID) {
-		return "", fmt.Errorf("reviewer is not assigned to this PR")
+		return "", apperr.ErrNotAssigned
 	}
 
-	oldReviewer, err := s.repo.GetUser(oldUserID)
+	author, err := s.repo.GetUser(pr.AuthorID)
 	if err != nil {
-		return "", fmt.Errorf("old reviewer not found")
+		return "", fmt.Errorf("author not found: %w", err)
 	}
 
-	availableUsers, err := s.repo.GetActiveUsersByTeam(oldReviewer.TeamName, pr.AssignedReviewers)
+	settings, err := s.repo.GetTeamSettings(author.TeamName)
 	if err != nil {
-		return "", fmt.Errorf("get available candidates: %w", err)
+		return "", fmt.Errorf("get team settings: %w", err)
 	}
 
-	if len(availableUsers) == 0 {
-		return "", fmt.Errorf("no active replacement candidate in team")
+	// Замена подбирается так же, как при назначении: из команды автора,
+	// затем из её партнёров и, при allow_cross_team, из остальных команд
+	excludeIDs := append([]string{pr.AuthorID}, pr.AssignedReviewers...)
+
+	selected, fallback, err := s.pickReviewers(author.TeamName, pr.PullRequestName, settings, excludeIDs, 1)
+	if err != nil {
+		return "", err
 	}
 
-	newReviewer := s.repo.GetRandomReviewers(availableUsers, 1)[0]
+	if len(selected) == 0 {
+		return "", apperr.ErrNoCandidate
+	}
+	newReviewer := selected[0]
 
 	for i, reviewerID := range pr.AssignedReviewers {
 		if reviewerID == oldUserID {
@@ -124,31 +303,185 @@ func (s *ReviewService) ReassignReviewer(pullRequestID string, oldUserID string)
 			break
 		}
 	}
+	pr.FallbackReviewers = append(removeString(pr.FallbackReviewers, oldUserID), fallback...)
 
 	if err := s.repo.UpdatePRReviewers(pr); err != nil {
 		return "", fmt.Errorf("failed to update PR: %w", err)
 	}
 
+	err = s.addEvent(models.PREvent{
+		PullRequestID:  pullRequestID,
+		EventType:      models.EventReviewerReassigned,
+		ActorID:        actorID,
+		UserID:         newReviewer,
+		PreviousUserID: oldUserID,
+		Reason:         reason,
+	})
+	if err != nil {
+		return "", err
+	}
+
 	return newReviewer, nil
 }
 
 // Team management methods
 func (s *ReviewService) CreateTeam(team *models.Team) error {
+	if err := validateStrategy(team.ReviewerStrategy, team.StrategyParams); err != nil {
+		return err
+	}
+
 	existingTeam, _ := s.repo.GetTeam(team.TeamName)
 	if existingTeam != nil {
-		return fmt.Errorf("team_name already exists")
+		return apperr.ErrTeamExists
+	}
+
+	if err := s.validatePartnerTeams(team.TeamName, team.PartnerTeams); err != nil {
+		return err
 	}
 
 	return s.repo.CreateTeam(team)
 }
 
-func (s *ReviewService) SetUserActive(userID string, isActive bool) (*models.User, error) {
+func (s *ReviewService) SetTeamPartners(teamName string, partnerTeams []string) (*models.Team, error) {
+	if _, err := s.repo.GetTeam(teamName); err != nil {
+		return nil, err
+	}
+
+	if err := s.validatePartnerTeams(teamName, partnerTeams); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.SetTeamPartners(teamName, partnerTeams); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTeam(teamName)
+}
+
+func (s *ReviewService) validatePartnerTeams(teamName string, partnerTeams []string) error {
+	seen := make(map[string]bool, len(partnerTeams))
+	for _, partner := range partnerTeams {
+		if partner == "" || partner == teamName || seen[partner] {
+			return apperr.New(apperr.CodeBadRequest, "invalid partner teams")
+		}
+		seen[partner] = true
+
+		if _, err := s.repo.GetTeam(partner); err != nil {
+			if errors.Is(err, apperr.ErrTeamNotFound) {
+				return apperr.New(apperr.CodeNotFound, "partner team not found")
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *ReviewService) GetTeamSettings(teamName string) (*models.TeamSettings, error) {
+	settings, err := s.repo.GetTeamSettings(teamName)
+	if err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// UpdateTeamSettings меняет заданные в patch настройки команды; остальные
+// сохраняют текущие значения
+func (s *ReviewService) UpdateTeamSettings(patch *models.TeamSettingsPatch) (*models.TeamSettings, error) {
+	var updated *models.TeamSettings
+
+	err := s.inTx(func(txs *ReviewService) error {
+		settings, err := txs.repo.GetTeamSettings(patch.TeamName)
+		if err != nil {
+			return err
+		}
+
+		patch.ApplyTo(settings)
+		if err := validateTeamSettings(settings); err != nil {
+			return err
+		}
+
+		if err := txs.repo.UpsertTeamSettings(settings); err != nil {
+			return err
+		}
+
+		updated = settings
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func validateTeamSettings(settings *models.TeamSettings) error {
+	if settings.MinReviewers < 0 || settings.MaxReviewers < 1 || settings.MinReviewers > settings.MaxReviewers ||
+		settings.RequiredApprovals < 0 {
+		return apperr.New(apperr.CodeBadRequest, "invalid team settings")
+	}
+	return nil
+}
+
+func (s *ReviewService) SetTeamStrategy(teamName string, strategy models.ReviewerStrategy, params models.StrategyParams) (*models.Team, error) {
+	if err := validateStrategy(strategy, params); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTeamStrategy(teamName, strategy, params); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetTeam(teamName)
+}
+
+// selectReviewers выбирает count ревьюеров из кандидатов по стратегии команды
+func (s *ReviewService) selectReviewers(team *models.Team, prName string, candidates []models.User, count int) ([]string, error) {
+	if count <= 0 || len(candidates) == 0 {
+		return []string{}, nil
+	}
+
+	selector, err := NewReviewerSelector(team.ReviewerStrategy, team.StrategyParams, s.repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return selector.Select(SelectionRequest{
+		TeamName:   team.TeamName,
+		PRName:     prName,
+		Candidates: candidates,
+		Count:      count,
+	})
+}
+
+// SetUserActive меняет активность пользователя; изменение записывается в журнал событий
+func (s *ReviewService) SetUserActive(userID string, isActive bool, actorID, reason string) (*models.User, error) {
 	user, err := s.repo.GetUser(userID)
 	if err != nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, err
+	}
+
+	if user.IsActive == isActive {
+		return user, nil
 	}
 
-	if err := s.repo.SetUserActive(userID, isActive); err != nil {
+	eventType := models.EventUserDeactivated
+	if isActive {
+		eventType = models.EventUserActivated
+	}
+
+	err = s.inTx(func(txs *ReviewService) error {
+		if err := txs.repo.SetUserActive(userID, isActive); err != nil {
+			return err
+		}
+
+		return txs.addEvent(models.PREvent{
+			EventType: eventType,
+			ActorID:   actorID,
+			UserID:    userID,
+			Reason:    reason,
+		})
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -165,3 +498,23 @@ func containsString(slice []string, item string) bool {
 	}
 	return false
 }
+
+func removeString(slice []string, item string) []string {
+	result := make([]string, 0, len(slice))
+	for _, v := range slice {
+		if v != item {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// utcTime приводит границу окна выборки к UTC. Столбцы времени в БД — TIMESTAMP
+// без часового пояса в UTC, и смещение клиента при сравнении отбрасывалось бы.
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
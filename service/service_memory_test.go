@@ -0,0 +1,795 @@
+package service
+
+import (
+	"encoding/json"
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"reviewtask/repo"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Тесты сервиса на хранилище в памяти: те же сценарии, что и на PostgreSQL,
+// но без базы данных
+
+func newMemoryService(t *testing.T) *ReviewService {
+	t.Helper()
+	return NewReviewService(repo.NewMemoryStore())
+}
+
+// createMemoryTeam создаёт команду; участники с префиксом "-" неактивны
+func createMemoryTeam(t *testing.T, svc *ReviewService, teamName string, userIDs ...string) {
+	t.Helper()
+
+	team := &models.Team{TeamName: teamName}
+	for _, userID := range userIDs {
+		isActive := true
+		if userID[0] == '-' {
+			userID, isActive = userID[1:], false
+		}
+		team.Members = append(team.Members, models.TeamMember{UserID: userID, Username: userID, IsActive: isActive})
+	}
+	require.NoError(t, svc.CreateTeam(team))
+}
+
+// settingsPatch возвращает изменение, заменяющее все настройки команды на settings
+func settingsPatch(settings *models.TeamSettings) *models.TeamSettingsPatch {
+	c := *settings
+	return &models.TeamSettingsPatch{
+		TeamName:                c.TeamName,
+		MinReviewers:            &c.MinReviewers,
+		MaxReviewers:            &c.MaxReviewers,
+		AllowCrossTeam:          &c.AllowCrossTeam,
+		FailOnInsufficient:      &c.FailOnInsufficient,
+		RequiredApprovals:       &c.RequiredApprovals,
+		BlockOnChangesRequested: &c.BlockOnChangesRequested,
+		RequireReviewer:         &c.RequireReviewer,
+		DisallowSelfMerge:       &c.DisallowSelfMerge,
+	}
+}
+
+func eventTypes(t *testing.T, svc *ReviewService, prID string) []models.EventType {
+	t.Helper()
+
+	events, err := svc.GetPRHistory(prID)
+	require.NoError(t, err)
+
+	types := []models.EventType{}
+	for _, event := range events {
+		types = append(types, event.EventType)
+	}
+	return types
+}
+
+func TestMemoryCreatePRAssignsActiveTeammates(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3", "-u4")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	assert.Equal(t, models.StatusOpen, pr.Status)
+	assert.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+	assert.Empty(t, pr.FallbackReviewers)
+	assert.Equal(t, []models.EventType{
+		models.EventPRCreated,
+		models.EventReviewerAssigned,
+		models.EventReviewerAssigned,
+	}, eventTypes(t, svc, "pr-1"))
+
+	stored, err := svc.repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, pr.AssignedReviewers, stored.AssignedReviewers)
+}
+
+func TestMemoryCreatePRRespectsMaxReviewers(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3", "u4", "u5")
+
+	settings := models.DefaultTeamSettings("backend")
+	settings.MaxReviewers = 3
+	_, err := svc.UpdateTeamSettings(settingsPatch(settings))
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	assert.Len(t, pr.AssignedReviewers, 3)
+	assert.NotContains(t, pr.AssignedReviewers, "u1")
+}
+
+func TestMemoryCreatePRSkipsAbsentUsers(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3")
+
+	now := time.Now()
+	_, err := svc.AddAbsence(&models.Absence{UserID: "u2", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)})
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u3"}, pr.AssignedReviewers)
+}
+
+func TestMemoryCreatePRErrors(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	_, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	_, err = svc.CreatePRWithReviewers("pr-1", "Add search again", "u1", false)
+	assert.ErrorIs(t, err, apperr.ErrPRExists)
+
+	_, err = svc.CreatePRWithReviewers("pr-2", "Add search", "ghost", false)
+	assert.ErrorIs(t, err, apperr.ErrAuthorNotFound)
+}
+
+func TestMemoryUpdateTeamSettingsKeepsOmittedFields(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	settings := models.DefaultTeamSettings("backend")
+	settings.AllowCrossTeam = true
+	settings.FailOnInsufficient = true
+	_, err := svc.UpdateTeamSettings(settingsPatch(settings))
+	require.NoError(t, err)
+
+	minReviewers, maxReviewers := 2, 3
+	updated, err := svc.UpdateTeamSettings(&models.TeamSettingsPatch{
+		TeamName:     "backend",
+		MinReviewers: &minReviewers,
+		MaxReviewers: &maxReviewers,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.MinReviewers)
+	assert.Equal(t, 3, updated.MaxReviewers)
+	assert.True(t, updated.AllowCrossTeam)
+	assert.True(t, updated.FailOnInsufficient)
+
+	stored, err := svc.GetTeamSettings("backend")
+	require.NoError(t, err)
+	assert.Equal(t, updated, stored)
+
+	// Проверяется итог: min_reviewers больше сохранённого max_reviewers
+	tooMany := 4
+	_, err = svc.UpdateTeamSettings(&models.TeamSettingsPatch{TeamName: "backend", MinReviewers: &tooMany})
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""))
+
+	_, err = svc.UpdateTeamSettings(&models.TeamSettingsPatch{TeamName: "ghost", MinReviewers: &minReviewers})
+	assert.ErrorIs(t, err, apperr.ErrTeamNotFound)
+}
+
+func TestMemoryCreatePRFailsOnInsufficientReviewers(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "-u3")
+
+	settings := models.DefaultTeamSettings("backend")
+	settings.MinReviewers = 2
+	settings.FailOnInsufficient = true
+	_, err := svc.UpdateTeamSettings(settingsPatch(settings))
+	require.NoError(t, err)
+
+	_, err = svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	assert.ErrorIs(t, err, apperr.ErrNotEnoughReviewers)
+
+	_, err = svc.repo.GetPR("pr-1")
+	assert.ErrorIs(t, err, apperr.ErrPRNotFound)
+
+	// Без fail_on_insufficient PR создаётся с теми ревьюерами, что нашлись
+	settings.FailOnInsufficient = false
+	_, err = svc.UpdateTeamSettings(settingsPatch(settings))
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+}
+
+func TestMemoryCreatePRFallsBackToPartnerTeams(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "frontend", "f1")
+	createMemoryTeam(t, svc, "platform", "p1")
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	_, err := svc.SetTeamPartners("backend", []string{"frontend"})
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", "f1"}, pr.AssignedReviewers)
+	assert.Equal(t, []string{"f1"}, pr.FallbackReviewers)
+
+	stored, err := svc.repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"f1"}, stored.FallbackReviewers)
+}
+
+func TestMemoryCreatePRCrossTeam(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "platform", "p1")
+	createMemoryTeam(t, svc, "backend", "u1")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	assert.Empty(t, pr.AssignedReviewers)
+
+	settings := models.DefaultTeamSettings("backend")
+	settings.AllowCrossTeam = true
+	_, err = svc.UpdateTeamSettings(settingsPatch(settings))
+	require.NoError(t, err)
+
+	pr, err = svc.CreatePRWithReviewers("pr-2", "Add search", "u1", false)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p1"}, pr.AssignedReviewers)
+	assert.Equal(t, []string{"p1"}, pr.FallbackReviewers)
+}
+
+func TestMemoryDraftGetsReviewersWhenReady(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", true)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDraft, pr.Status)
+	assert.Empty(t, pr.AssignedReviewers)
+
+	pr, err = svc.MarkReady("pr-1", "u1", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusOpen, pr.Status)
+	assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+
+	_, err = svc.MarkReady("pr-1", "u1", "")
+	assert.ErrorIs(t, err, apperr.ErrInvalidTransition)
+}
+
+func TestMemoryReassignReviewer(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3", "u4")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	oldReviewer := pr.AssignedReviewers[0]
+	newReviewer, err := svc.ReassignReviewer("pr-1", oldReviewer, "u1", "on vacation")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, oldReviewer, newReviewer)
+	assert.NotEqual(t, "u1", newReviewer)
+	assert.NotContains(t, pr.AssignedReviewers, newReviewer)
+
+	updated, err := svc.repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{pr.AssignedReviewers[1], newReviewer}, updated.AssignedReviewers)
+
+	events, err := svc.GetPRHistory("pr-1")
+	require.NoError(t, err)
+	last := events[len(events)-1]
+	assert.Equal(t, models.EventReviewerReassigned, last.EventType)
+	assert.Equal(t, oldReviewer, last.PreviousUserID)
+	assert.Equal(t, newReviewer, last.UserID)
+	assert.Equal(t, "on vacation", last.Reason)
+
+	// Заменённый ревьюер больше не назначен
+	_, err = svc.ReassignReviewer("pr-1", oldReviewer, "u1", "")
+	assert.ErrorIs(t, err, apperr.ErrNotAssigned)
+}
+
+// Замена ревьюера из команды-партнёра подбирается по партнёрам команды автора
+// и остаётся в fallback_reviewers
+func TestMemoryReassignFallbackReviewer(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "platform", "p1")
+	createMemoryTeam(t, svc, "frontend", "f1", "f2")
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	_, err := svc.SetTeamPartners("backend", []string{"frontend"})
+	require.NoError(t, err)
+	_, err = svc.SetTeamPartners("frontend", []string{"platform"})
+	require.NoError(t, err)
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	require.Len(t, pr.FallbackReviewers, 1)
+	oldFallback := pr.FallbackReviewers[0]
+
+	newReviewer, err := svc.ReassignReviewer("pr-1", oldFallback, "u1", "")
+	require.NoError(t, err)
+	assert.Contains(t, []string{"f1", "f2"}, newReviewer)
+	assert.NotEqual(t, oldFallback, newReviewer)
+
+	updated, err := svc.repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"u2", newReviewer}, updated.AssignedReviewers)
+	assert.Equal(t, []string{newReviewer}, updated.FallbackReviewers)
+
+	// Партнёры команды-партнёра (platform) не используются
+	_, err = svc.SetUserActive(oldFallback, false, "u1", "")
+	require.NoError(t, err)
+	_, err = svc.ReassignReviewer("pr-1", newReviewer, "u1", "")
+	assert.ErrorIs(t, err, apperr.ErrNoCandidate)
+}
+
+func TestMemoryReassignErrors(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	// Все участники команды, кроме автора, уже назначены
+	_, err = svc.ReassignReviewer("pr-1", "u2", "u1", "")
+	assert.ErrorIs(t, err, apperr.ErrNoCandidate)
+
+	_, err = svc.MergePR("pr-1", "u1")
+	require.NoError(t, err)
+
+	_, err = svc.ReassignReviewer("pr-1", pr.AssignedReviewers[0], "u1", "")
+	assert.ErrorIs(t, err, apperr.ErrPRMerged)
+
+	_, err = svc.ReassignReviewer("missing", "u2", "u1", "")
+	assert.ErrorIs(t, err, apperr.ErrPRNotFound)
+}
+
+func TestMemoryMergeIsIdempotent(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	_, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	merged, err := svc.MergePR("pr-1", "u2")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusMerged, merged.Status)
+	require.NotNil(t, merged.MergedAt)
+
+	again, err := svc.MergePR("pr-1", "u2")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusMerged, again.Status)
+	assert.Equal(t, *merged.MergedAt, *again.MergedAt)
+
+	merges := 0
+	for _, eventType := range eventTypes(t, svc, "pr-1") {
+		if eventType == models.EventPRMerged {
+			merges++
+		}
+	}
+	assert.Equal(t, 1, merges)
+}
+
+func TestMemoryMergeRules(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	settings := models.DefaultTeamSettings("backend")
+	settings.RequiredApprovals = 1
+	settings.DisallowSelfMerge = true
+	_, err := svc.UpdateTeamSettings(settingsPatch(settings))
+	require.NoError(t, err)
+
+	_, err = svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	_, err = svc.MergePR("pr-1", "u1")
+	assert.ErrorIs(t, err, apperr.ErrMergeBlocked)
+	appErr, ok := apperr.From(err)
+	require.True(t, ok)
+	assert.Equal(t, []string{RuleRequiredApprovals, RuleNoSelfMerge},
+		violatedRules(appErr.Details["failed_rules"].([]MergeRuleViolation)))
+
+	pr, err := svc.repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusOpen, pr.Status)
+
+	_, err = svc.SubmitReview("pr-1", "u2", models.VerdictApproved, "LGTM")
+	require.NoError(t, err)
+
+	merged, err := svc.MergePR("pr-1", "u2")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusMerged, merged.Status)
+}
+
+func TestMemoryRecordMergeBypassesRules(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	settings := models.DefaultTeamSettings("backend")
+	settings.RequiredApprovals = 1
+	settings.DisallowSelfMerge = true
+	_, err := svc.UpdateTeamSettings(settingsPatch(settings))
+	require.NoError(t, err)
+
+	_, err = svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	_, err = svc.MergePR("pr-1", "u1")
+	require.ErrorIs(t, err, apperr.ErrMergeBlocked)
+
+	// Мердж на хостинге уже произошёл: он записывается вопреки правилам команды
+	merged, err := svc.RecordMerge("pr-1", "u1", "github webhook")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusMerged, merged.Status)
+
+	again, err := svc.RecordMerge("pr-1", "u1", "github webhook")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusMerged, again.Status)
+
+	// Закрытый в сервисе PR тоже можно отметить смердженным
+	_, err = svc.CreatePRWithReviewers("pr-2", "Fix search", "u1", false)
+	require.NoError(t, err)
+	_, err = svc.ClosePR("pr-2", "u1", "")
+	require.NoError(t, err)
+	merged, err = svc.RecordMerge("pr-2", "u2", "gitlab webhook")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusMerged, merged.Status)
+
+	history, err := svc.GetPRHistory("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []models.EventType{models.EventPRCreated, models.EventReviewerAssigned, models.EventPRMerged}, eventTypes(t, svc, "pr-1"))
+	last := history[len(history)-1]
+	assert.Equal(t, "u1", last.ActorID)
+	assert.Equal(t, "github webhook", last.Reason)
+}
+
+func TestMemorySubmitReview(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3", "u4")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	reviewer := pr.AssignedReviewers[0]
+	updated, err := svc.SubmitReview("pr-1", reviewer, models.VerdictChangesRequested, "needs tests")
+	require.NoError(t, err)
+	require.Len(t, updated.Reviews, 1)
+	assert.Equal(t, reviewer, updated.Reviews[0].UserID)
+	assert.Equal(t, models.VerdictChangesRequested, updated.Reviews[0].Verdict)
+
+	_, err = svc.SubmitReview("pr-1", "u1", models.VerdictApproved, "")
+	assert.ErrorIs(t, err, apperr.ErrNotAssigned)
+
+	// Вердикт снятого ревьюера не учитывается
+	_, err = svc.ReassignReviewer("pr-1", reviewer, "u1", "")
+	require.NoError(t, err)
+	updated, err = svc.repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Empty(t, updated.Reviews)
+}
+
+func TestMemoryLifecycle(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	_, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	closed, err := svc.ClosePR("pr-1", "u1", "not needed")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusClosed, closed.Status)
+	assert.NotNil(t, closed.ClosedAt)
+
+	_, err = svc.MergePR("pr-1", "u2")
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeInvalidTransition, ""))
+
+	reopened, err := svc.ReopenPR("pr-1", "u1", "")
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusReopened, reopened.Status)
+	assert.Nil(t, reopened.ClosedAt)
+	assert.Equal(t, []string{"u2"}, reopened.AssignedReviewers)
+
+	assert.Equal(t, []models.EventType{
+		models.EventPRCreated,
+		models.EventReviewerAssigned,
+		models.EventPRClosed,
+		models.EventPRReopened,
+	}, eventTypes(t, svc, "pr-1"))
+}
+
+func TestMemoryDeactivateUsersReassignsReviews(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3", "u4")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	first, second := pr.AssignedReviewers[0], pr.AssignedReviewers[1]
+
+	report, err := svc.DeactivateUsers([]string{first}, "", "admin", "left company")
+	require.NoError(t, err)
+	assert.Equal(t, []string{first}, report.Deactivated)
+	require.Len(t, report.Reassignments, 1)
+	assert.True(t, report.Reassignments[0].Reassigned)
+
+	user, err := svc.repo.GetUser(first)
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+
+	pr, err = svc.repo.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.NotContains(t, pr.AssignedReviewers, first)
+	assert.Len(t, pr.AssignedReviewers, 2)
+
+	// Заменить второго ревьюера некем: ревью остаётся за ним и попадает в отчёт с ошибкой
+	report, err = svc.DeactivateUsers([]string{second}, "", "admin", "")
+	require.NoError(t, err)
+	require.Len(t, report.Reassignments, 1)
+	assert.False(t, report.Reassignments[0].Reassigned)
+	assert.NotEmpty(t, report.Reassignments[0].Error)
+}
+
+func TestMemoryMoveUserToTeam(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3")
+	createMemoryTeam(t, svc, "frontend", "f1")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	require.Len(t, pr.AssignedReviewers, 2)
+
+	change, err := svc.MoveUserToTeam("u2", "frontend", false, "admin", "")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", change.PreviousTeamName)
+	assert.Equal(t, "frontend", change.User.TeamName)
+
+	_, err = svc.MoveUserToTeam("u2", "frontend", false, "admin", "")
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeUserInTeam, ""))
+
+	team, err := svc.repo.GetTeam("frontend")
+	require.NoError(t, err)
+	assert.Len(t, team.Members, 2)
+}
+
+func TestMemorySyncTeam(t *testing.T) {
+	svc := newMemoryService(t)
+
+	result, err := svc.SyncTeam(&models.Team{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "Alice", IsActive: true},
+			{UserID: "u2", Username: "Bob", IsActive: true},
+		},
+	}, false, true, "admin", "")
+	require.NoError(t, err)
+	assert.True(t, result.CreatedTeam)
+	assert.ElementsMatch(t, []string{"u1", "u2"}, result.Added)
+
+	result, err = svc.SyncTeam(&models.Team{
+		TeamName: "backend",
+		Members:  []models.TeamMember{{UserID: "u1", Username: "Alice", IsActive: true}},
+	}, true, true, "admin", "")
+	require.NoError(t, err)
+	assert.False(t, result.CreatedTeam)
+	assert.Equal(t, []string{"u1"}, result.Unchanged)
+	assert.Equal(t, []string{"u2"}, result.Deactivated)
+}
+
+func TestMemoryGetUserReviewPRsPagination(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+	createMemoryTeam(t, svc, "frontend", "f1")
+
+	_, err := svc.SetTeamPartners("frontend", []string{"backend"})
+	require.NoError(t, err)
+
+	for _, id := range []string{"pr-1", "pr-2", "pr-3", "pr-4", "pr-5"} {
+		_, err := svc.CreatePRWithReviewers(id, "Backend "+id, "u1", false)
+		require.NoError(t, err)
+	}
+	_, err = svc.CreatePRWithReviewers("pr-f", "Frontend", "f1", false)
+	require.NoError(t, err)
+	_, err = svc.MergePR("pr-2", "u2")
+	require.NoError(t, err)
+
+	ids := func(page *models.PRPage) []string {
+		result := []string{}
+		for _, pr := range page.PullRequests {
+			result = append(result, pr.PullRequestID)
+		}
+		return result
+	}
+
+	page, err := svc.GetUserReviewPRs("u2", models.PRFilter{Limit: 4})
+	require.NoError(t, err)
+	assert.Equal(t, 6, page.Total)
+	assert.Equal(t, []string{"pr-f", "pr-5", "pr-4", "pr-3"}, ids(page))
+	require.NotEmpty(t, page.NextCursor)
+
+	cursor, err := models.DecodePageCursor(page.NextCursor)
+	require.NoError(t, err)
+	page, err = svc.GetUserReviewPRs("u2", models.PRFilter{Limit: 4, Cursor: cursor})
+	require.NoError(t, err)
+	assert.Equal(t, 6, page.Total)
+	assert.Equal(t, []string{"pr-2", "pr-1"}, ids(page))
+	assert.Empty(t, page.NextCursor)
+
+	page, err = svc.GetUserReviewPRs("u2", models.PRFilter{
+		Statuses: []models.PRStatus{models.StatusOpen},
+		TeamName: "backend",
+		Order:    models.SortAsc,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 4, page.Total)
+	assert.Equal(t, []string{"pr-1", "pr-3", "pr-4", "pr-5"}, ids(page))
+
+	page, err = svc.GetUserReviewPRs("u2", models.PRFilter{AuthorID: "f1"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pr-f"}, ids(page))
+
+	future := time.Now().Add(time.Hour)
+	page, err = svc.GetUserReviewPRs("u2", models.PRFilter{CreatedFrom: &future})
+	require.NoError(t, err)
+	assert.Equal(t, 0, page.Total)
+	assert.Empty(t, page.PullRequests)
+}
+
+func TestMemoryGetUserReviewPRsValidation(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1")
+
+	badRequest := apperr.New(apperr.CodeBadRequest, "")
+	_, err := svc.GetUserReviewPRs("u1", models.PRFilter{Limit: MaxPageSize + 1})
+	assert.ErrorIs(t, err, badRequest)
+	_, err = svc.GetUserReviewPRs("u1", models.PRFilter{Order: "sideways"})
+	assert.ErrorIs(t, err, badRequest)
+	_, err = svc.GetUserReviewPRs("u1", models.PRFilter{Statuses: []models.PRStatus{"DONE"}})
+	assert.ErrorIs(t, err, badRequest)
+	_, err = svc.GetUserReviewPRs("ghost", models.PRFilter{})
+	assert.ErrorIs(t, err, apperr.ErrUserNotFound)
+}
+
+func TestMemoryListPRs(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3")
+	createMemoryTeam(t, svc, "frontend", "f1", "f2")
+
+	for _, pr := range []struct{ id, name, author string }{
+		{"pr-1", "Add search API", "u1"},
+		{"pr-2", "Fix search_index 100%", "u1"},
+		{"pr-3", "Login page", "f1"},
+		{"pr-4", "Search box", "f1"},
+	} {
+		_, err := svc.CreatePRWithReviewers(pr.id, pr.name, pr.author, false)
+		require.NoError(t, err)
+	}
+	_, err := svc.MergePR("pr-3", "f2")
+	require.NoError(t, err)
+	_, err = svc.MergePR("pr-1", "u2")
+	require.NoError(t, err)
+
+	ids := func(filter models.PRFilter) []string {
+		page, err := svc.ListPRs(filter)
+		require.NoError(t, err)
+		result := []string{}
+		for _, pr := range page.PullRequests {
+			result = append(result, pr.PullRequestID)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"pr-4", "pr-3", "pr-2", "pr-1"}, ids(models.PRFilter{}))
+	assert.Equal(t, []string{"pr-4", "pr-2", "pr-1"}, ids(models.PRFilter{NameContains: "SEARCH"}))
+	assert.Equal(t, []string{"pr-2"}, ids(models.PRFilter{NameContains: "h_i"}))
+	assert.Equal(t, []string{"pr-2"}, ids(models.PRFilter{NameContains: "100%"}))
+	assert.Equal(t, []string{"pr-4", "pr-3"}, ids(models.PRFilter{TeamName: "frontend"}))
+	assert.Equal(t, []string{"pr-4", "pr-3"}, ids(models.PRFilter{ReviewerID: "f2"}))
+	assert.Equal(t, []string{"pr-2"}, ids(models.PRFilter{Statuses: []models.PRStatus{models.StatusOpen}, AuthorID: "u1"}))
+
+	// Сортировка по времени мерджа оставляет только смердженные PR
+	assert.Equal(t, []string{"pr-3", "pr-1"}, ids(models.PRFilter{SortBy: models.SortByMergedAt, Order: models.SortAsc}))
+
+	page, err := svc.ListPRs(models.PRFilter{SortBy: models.SortByMergedAt, Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	require.Len(t, page.PullRequests, 1)
+	assert.Equal(t, "pr-1", page.PullRequests[0].PullRequestID)
+	assert.NotNil(t, page.PullRequests[0].MergedAt)
+
+	cursor, err := models.DecodePageCursor(page.NextCursor)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"pr-3"}, ids(models.PRFilter{SortBy: models.SortByMergedAt, Limit: 1, Cursor: cursor}))
+
+	// Курсор действителен только для той сортировки, с которой он выдан
+	_, err = svc.ListPRs(models.PRFilter{Limit: 1, Cursor: cursor})
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""))
+	_, err = svc.ListPRs(models.PRFilter{SortBy: models.SortByMergedAt, Order: models.SortAsc, Limit: 1, Cursor: cursor})
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""))
+
+	future := time.Now().Add(time.Hour)
+	assert.Empty(t, ids(models.PRFilter{MergedFrom: &future}))
+
+	_, err = svc.ListPRs(models.PRFilter{SortBy: "name"})
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""))
+}
+
+func TestListPRsWindowsAreUTC(t *testing.T) {
+	store := &filterRecordingStore{MemoryStore: repo.NewMemoryStore()}
+	svc := NewReviewService(store)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	moscow := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, moscow)
+	to := from.Add(24 * time.Hour)
+	cursor := &models.PageCursor{SortBy: models.SortByCreatedAt, Order: models.SortDesc, Time: from.Add(time.Hour), ID: "pr-1"}
+
+	_, err := svc.ListPRs(models.PRFilter{CreatedFrom: &from, CreatedTo: &to, MergedFrom: &from, MergedTo: &to, Cursor: cursor})
+	require.NoError(t, err)
+	_, err = svc.GetUserReviewPRs("u2", models.PRFilter{CreatedFrom: &from, CreatedTo: &to})
+	require.NoError(t, err)
+
+	require.Len(t, store.prs, 2)
+	got := store.prs[0]
+	assert.Equal(t, time.Date(2023, 12, 31, 21, 0, 0, 0, time.UTC), *got.CreatedFrom)
+	for _, bound := range []*time.Time{got.CreatedFrom, got.CreatedTo, got.MergedFrom, got.MergedTo, &got.Cursor.Time} {
+		assert.Equal(t, time.UTC, bound.Location())
+	}
+	assert.True(t, got.Cursor.Time.Equal(cursor.Time))
+	// Курсор вызывающего не меняется
+	assert.Equal(t, moscow, cursor.Time.Location())
+
+	// /users/getReview проходит ту же нормализацию
+	assert.Equal(t, time.UTC, store.prs[1].CreatedFrom.Location())
+	assert.Equal(t, time.UTC, store.prs[1].CreatedTo.Location())
+}
+
+func TestMemoryGetPR(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	_, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	_, err = svc.SubmitReview("pr-1", "u2", models.VerdictApproved, "")
+	require.NoError(t, err)
+
+	pr, err := svc.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"u2"}, pr.AssignedReviewers)
+	require.Len(t, pr.Reviews, 1)
+
+	_, err = svc.GetPR("missing")
+	assert.ErrorIs(t, err, apperr.ErrPRNotFound)
+}
+
+func TestMemoryOutboxRecordsEvents(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svc := NewReviewService(store)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+	createMemoryTeam(t, svc, "frontend", "f1", "f2")
+
+	_, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	_, err = svc.SetUserActive("f2", false, "admin", "left")
+	require.NoError(t, err)
+
+	pending, err := store.ClaimOutboxEvents(time.Now().Add(time.Hour), 100, time.Minute)
+	require.NoError(t, err)
+
+	journal := eventTypes(t, svc, "pr-1")
+	require.Len(t, pending, len(journal)+1)
+	for i, eventType := range journal {
+		assert.Equal(t, eventType, pending[i].EventType)
+		assert.Equal(t, "backend", pending[i].TeamName)
+	}
+
+	assigned := pending[1]
+	assert.Equal(t, models.EventIdempotencyKey(assigned.EventID), assigned.IdempotencyKey)
+
+	var envelope models.EventEnvelope
+	require.NoError(t, json.Unmarshal(assigned.Payload, &envelope))
+	assert.Equal(t, assigned.IdempotencyKey, envelope.IdempotencyKey)
+	assert.Equal(t, "u2", envelope.Event.UserID)
+	require.NotNil(t, envelope.PullRequest)
+	assert.Equal(t, []string{"u2"}, envelope.PullRequest.AssignedReviewers)
+
+	deactivated := pending[len(pending)-1]
+	assert.Equal(t, models.EventUserDeactivated, deactivated.EventType)
+	assert.Equal(t, "frontend", deactivated.TeamName)
+
+	// Ключ идемпотентности не даёт записать событие дважды
+	duplicate := deactivated
+	require.NoError(t, store.AddOutboxEvent(&duplicate))
+	require.NoError(t, store.CompleteOutboxEvent(deactivated.ID))
+	pending, err = store.ClaimOutboxEvents(time.Now().Add(2*time.Hour), 100, time.Minute)
+	require.NoError(t, err)
+	assert.Len(t, pending, len(journal))
+}
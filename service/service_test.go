@@ -1,6 +1,7 @@
 package service
 
 import (
+	"reviewtask/models"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -46,3 +47,63 @@ func TestContainsString(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTeamSettings(t *testing.T) {
+	valid := models.DefaultTeamSettings("backend")
+	assert.NoError(t, validateTeamSettings(valid))
+
+	assert.Error(t, validateTeamSettings(&models.TeamSettings{MinReviewers: 3, MaxReviewers: 2}))
+	assert.Error(t, validateTeamSettings(&models.TeamSettings{MinReviewers: -1, MaxReviewers: 2}))
+	assert.Error(t, validateTeamSettings(&models.TeamSettings{MinReviewers: 0, MaxReviewers: 0}))
+	assert.NoError(t, validateTeamSettings(&models.TeamSettings{MinReviewers: 0, MaxReviewers: 1}))
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from     models.PRStatus
+		to       models.PRStatus
+		expected bool
+	}{
+		{models.StatusDraft, models.StatusOpen, true},
+		{models.StatusDraft, models.StatusClosed, true},
+		{models.StatusDraft, models.StatusMerged, false},
+		{models.StatusOpen, models.StatusMerged, true},
+		{models.StatusOpen, models.StatusClosed, true},
+		{models.StatusOpen, models.StatusReopened, false},
+		{models.StatusClosed, models.StatusReopened, true},
+		{models.StatusClosed, models.StatusMerged, false},
+		{models.StatusReopened, models.StatusMerged, true},
+		{models.StatusReopened, models.StatusClosed, true},
+		{models.StatusMerged, models.StatusClosed, false},
+		{models.StatusMerged, models.StatusReopened, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			assert.Equal(t, tt.expected, canTransition(tt.from, tt.to))
+		})
+	}
+}
+
+func TestIsValidVerdict(t *testing.T) {
+	assert.True(t, isValidVerdict(models.VerdictApproved))
+	assert.True(t, isValidVerdict(models.VerdictChangesRequested))
+	assert.True(t, isValidVerdict(models.VerdictCommented))
+	assert.False(t, isValidVerdict("LGTM"))
+	assert.False(t, isValidVerdict(""))
+}
+
+func TestValidateRoster(t *testing.T) {
+	assert.NoError(t, validateRoster(nil))
+	assert.NoError(t, validateRoster([]models.TeamMember{
+		{UserID: "u1", Username: "Alice", IsActive: true},
+		{UserID: "u2", Username: "Bob"},
+	}))
+
+	assert.Error(t, validateRoster([]models.TeamMember{{UserID: "", Username: "Alice"}}))
+	assert.Error(t, validateRoster([]models.TeamMember{{UserID: "u1", Username: ""}}))
+	assert.Error(t, validateRoster([]models.TeamMember{
+		{UserID: "u1", Username: "Alice"},
+		{UserID: "u1", Username: "Alice 2"},
+	}))
+}
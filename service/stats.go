@@ -0,0 +1,77 @@
+package service
+
+import (
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// normalizeStatsFilter проверяет окно выборки и приводит его границы к UTC
+func normalizeStatsFilter(filter *models.StatsFilter) error {
+	filter.From, filter.To = utcTime(filter.From), utcTime(filter.To)
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return apperr.New(apperr.CodeBadRequest, "from must be before to")
+	}
+	return nil
+}
+
+// GetUserStats возвращает назначения на ревью по пользователям
+func (s *ReviewService) GetUserStats(filter models.StatsFilter) ([]models.UserStats, error) {
+	if err := normalizeStatsFilter(&filter); err != nil {
+		return nil, err
+	}
+	return s.repo.GetUserStats(filter)
+}
+
+// GetTeamStats возвращает PR и назначения по командам
+func (s *ReviewService) GetTeamStats(filter models.StatsFilter) ([]models.TeamStats, error) {
+	if err := normalizeStatsFilter(&filter); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTeamStats(filter)
+}
+
+// GetMergeTimeStats возвращает среднее время до мерджа по командам и в целом
+func (s *ReviewService) GetMergeTimeStats(filter models.StatsFilter) (*models.MergeTimeReport, error) {
+	if err := normalizeStatsFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	teams, err := s.repo.GetMergeTimeStats(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.MergeTimeReport{Overall: overallMergeTime(teams), Teams: teams}, nil
+}
+
+// GetReassignmentStats возвращает переназначения по пользователям
+func (s *ReviewService) GetReassignmentStats(filter models.StatsFilter) (*models.ReassignmentReport, error) {
+	if err := normalizeStatsFilter(&filter); err != nil {
+		return nil, err
+	}
+
+	users, err := s.repo.GetReassignmentStats(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ReassignmentReport{Users: users}
+	for _, u := range users {
+		report.TotalReassignments += u.ReassignedFrom
+	}
+	return report, nil
+}
+
+// overallMergeTime сводит статистику команд в общее среднее, взвешенное по числу PR
+func overallMergeTime(stats []models.MergeTimeStats) models.MergeTimeStats {
+	var overall models.MergeTimeStats
+	var totalSeconds float64
+	for _, s := range stats {
+		overall.MergedPRs += s.MergedPRs
+		totalSeconds += s.AvgMergeSeconds * float64(s.MergedPRs)
+	}
+	if overall.MergedPRs > 0 {
+		overall.AvgMergeSeconds = totalSeconds / float64(overall.MergedPRs)
+	}
+	return overall
+}
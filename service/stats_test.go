@@ -0,0 +1,145 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"reviewtask/repo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOverallMergeTime(t *testing.T) {
+	assert.Equal(t, models.MergeTimeStats{}, overallMergeTime(nil))
+
+	// Среднее взвешивается по числу PR, а не по числу команд
+	overall := overallMergeTime([]models.MergeTimeStats{
+		{TeamName: "backend", MergedPRs: 3, AvgMergeSeconds: 100},
+		{TeamName: "frontend", MergedPRs: 1, AvgMergeSeconds: 500},
+		{TeamName: "platform", MergedPRs: 0},
+	})
+	assert.Empty(t, overall.TeamName)
+	assert.Equal(t, 4, overall.MergedPRs)
+	assert.InDelta(t, 200, overall.AvgMergeSeconds, 1e-9)
+}
+
+func TestMemoryStats(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3", "u4")
+	createMemoryTeam(t, svc, "frontend", "f1", "f2")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	_, err = svc.CreatePRWithReviewers("pr-2", "Fix layout", "f1", false)
+	require.NoError(t, err)
+	_, err = svc.MergePR("pr-2", "f1")
+	require.NoError(t, err)
+
+	oldReviewer := pr.AssignedReviewers[0]
+	newReviewer, err := svc.ReassignReviewer("pr-1", oldReviewer, "u1", "")
+	require.NoError(t, err)
+
+	teams, err := svc.GetTeamStats(models.StatsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, []models.TeamStats{
+		{TeamName: "backend", PRsTotal: 1, PRsOpen: 1, OpenAssignments: 2, TotalAssignments: 3},
+		{TeamName: "frontend", PRsTotal: 1, PRsMerged: 1, TotalAssignments: 1},
+	}, teams)
+
+	users, err := svc.GetUserStats(models.StatsFilter{TeamName: "frontend"})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	assert.Equal(t, "f2", users[0].UserID)
+	assert.Equal(t, 1, users[0].TotalAssignments)
+	assert.Zero(t, users[0].OpenAssignments)
+
+	report, err := svc.GetReassignmentStats(models.StatsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.TotalReassignments)
+	assert.ElementsMatch(t, []models.ReassignmentStats{
+		{UserID: oldReviewer, TeamName: "backend", ReassignedFrom: 1},
+		{UserID: newReviewer, TeamName: "backend", ReassignedTo: 1},
+	}, report.Users)
+
+	mergeTime, err := svc.GetMergeTimeStats(models.StatsFilter{})
+	require.NoError(t, err)
+	require.Len(t, mergeTime.Teams, 1)
+	assert.Equal(t, "frontend", mergeTime.Teams[0].TeamName)
+	assert.Equal(t, 1, mergeTime.Overall.MergedPRs)
+
+	// Окно в будущем ничего не содержит
+	from := time.Now().Add(time.Hour)
+	report, err = svc.GetReassignmentStats(models.StatsFilter{From: &from})
+	require.NoError(t, err)
+	assert.Zero(t, report.TotalReassignments)
+	assert.Empty(t, report.Users)
+
+	to := from.Add(-2 * time.Hour)
+	_, err = svc.GetUserStats(models.StatsFilter{From: &from, To: &to})
+	assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""))
+}
+
+// filterRecordingStore запоминает фильтры, с которыми сервис обращается к хранилищу
+type filterRecordingStore struct {
+	*repo.MemoryStore
+	stats []models.StatsFilter
+	prs   []models.PRFilter
+}
+
+func (s *filterRecordingStore) ListPRs(filter models.PRFilter) (*models.PRPage, error) {
+	s.prs = append(s.prs, filter)
+	return s.MemoryStore.ListPRs(filter)
+}
+
+func (s *filterRecordingStore) GetUserStats(filter models.StatsFilter) ([]models.UserStats, error) {
+	s.stats = append(s.stats, filter)
+	return s.MemoryStore.GetUserStats(filter)
+}
+
+func (s *filterRecordingStore) GetTeamStats(filter models.StatsFilter) ([]models.TeamStats, error) {
+	s.stats = append(s.stats, filter)
+	return s.MemoryStore.GetTeamStats(filter)
+}
+
+func (s *filterRecordingStore) GetMergeTimeStats(filter models.StatsFilter) ([]models.MergeTimeStats, error) {
+	s.stats = append(s.stats, filter)
+	return s.MemoryStore.GetMergeTimeStats(filter)
+}
+
+func (s *filterRecordingStore) GetReassignmentStats(filter models.StatsFilter) ([]models.ReassignmentStats, error) {
+	s.stats = append(s.stats, filter)
+	return s.MemoryStore.GetReassignmentStats(filter)
+}
+
+func TestStatsWindowIsUTC(t *testing.T) {
+	store := &filterRecordingStore{MemoryStore: repo.NewMemoryStore()}
+	svc := NewReviewService(store)
+
+	// Полночь по Москве — 21:00 UTC предыдущего дня
+	moscow := time.FixedZone("MSK", 3*60*60)
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, moscow)
+	to := from.Add(24 * time.Hour)
+	filter := models.StatsFilter{From: &from, To: &to}
+
+	_, err := svc.GetUserStats(filter)
+	require.NoError(t, err)
+	_, err = svc.GetTeamStats(filter)
+	require.NoError(t, err)
+	_, err = svc.GetMergeTimeStats(filter)
+	require.NoError(t, err)
+	_, err = svc.GetReassignmentStats(filter)
+	require.NoError(t, err)
+
+	require.Len(t, store.stats, 4)
+	for _, got := range store.stats {
+		assert.Equal(t, time.Date(2023, 12, 31, 21, 0, 0, 0, time.UTC), *got.From)
+		assert.Equal(t, time.UTC, got.From.Location())
+		assert.Equal(t, time.UTC, got.To.Location())
+		assert.True(t, got.To.Equal(to))
+	}
+	// Фильтр вызывающего не меняется
+	assert.Equal(t, moscow, from.Location())
+}
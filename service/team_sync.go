@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// SyncTeam приводит состав команды к переданному списку участников.
+// Отсутствующая команда создаётся, новые пользователи добавляются,
+// участники других команд переводятся в неё, если moveMembers задан, иначе
+// не меняются и попадают в Conflicts. У остальных обновляются имя и активность.
+// При deactivateMissing участники, которых нет в списке, деактивируются.
+// Открытые ревью деактивированных и переведённых пользователей переназначаются.
+// Повторный вызов с тем же списком ничего не меняет.
+func (s *ReviewService) SyncTeam(team *models.Team, deactivateMissing, moveMembers bool, actorID, reason string) (*models.TeamSyncResult, error) {
+	if team.TeamName == "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "team_name is required")
+	}
+	if err := validateRoster(team.Members); err != nil {
+		return nil, err
+	}
+
+	result := &models.TeamSyncResult{
+		TeamName:      team.TeamName,
+		Added:         []string{},
+		Moved:         []string{},
+		Updated:       []string{},
+		Deactivated:   []string{},
+		Unchanged:     []string{},
+		Conflicts:     []string{},
+		Reassignments: []models.ReassignmentResult{},
+	}
+
+	err := s.inTx(func(txs *ReviewService) error {
+		current, err := txs.repo.GetTeam(team.TeamName)
+		switch {
+		case errors.Is(err, apperr.ErrTeamNotFound):
+			current = &models.Team{TeamName: team.TeamName}
+			if err := txs.repo.CreateTeam(&models.Team{TeamName: team.TeamName}); err != nil {
+				return fmt.Errorf("failed to create team: %w", err)
+			}
+			result.CreatedTeam = true
+		case err != nil:
+			return err
+		}
+
+		inRoster := make(map[string]bool, len(team.Members))
+		for _, member := range team.Members {
+			inRoster[member.UserID] = true
+		}
+
+		// Сначала деактивируем всех выбывших, чтобы ни их ревью, ни ревью
+		// деактивированных по списку не достались другому выбывшему
+		missing := []string{}
+		for _, member := range current.Members {
+			if !deactivateMissing || inRoster[member.UserID] || !member.IsActive {
+				continue
+			}
+			if _, err := txs.SetUserActive(member.UserID, false, actorID, reason); err != nil {
+				return err
+			}
+			missing = append(missing, member.UserID)
+		}
+
+		for _, member := range team.Members {
+			if err := txs.syncMember(team.TeamName, member, moveMembers, result, actorID, reason); err != nil {
+				return err
+			}
+		}
+
+		for _, userID := range missing {
+			reassignments, err := txs.reassignOpenReviews(userID, actorID, reason)
+			if err != nil {
+				return err
+			}
+			result.Deactivated = append(result.Deactivated, userID)
+			result.Reassignments = append(result.Reassignments, reassignments...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// syncMember приводит одного пользователя к записи из списка и отмечает изменение в result
+func (s *ReviewService) syncMember(teamName string, member models.TeamMember, moveMembers bool, result *models.TeamSyncResult, actorID, reason string) error {
+	desired := &models.User{
+		UserID:   member.UserID,
+		Username: member.Username,
+		TeamName: teamName,
+		IsActive: member.IsActive,
+	}
+
+	user, err := s.repo.GetUser(member.UserID)
+	switch {
+	case errors.Is(err, apperr.ErrUserNotFound):
+		if err := s.repo.CreateUser(desired); err != nil {
+			return fmt.Errorf("failed to create user: %w", err)
+		}
+		result.Added = append(result.Added, member.UserID)
+		return s.addEvent(models.PREvent{
+			EventType: models.EventUserTeamChanged,
+			ActorID:   actorID,
+			UserID:    member.UserID,
+			Reason:    reason,
+		})
+
+	case err != nil:
+		return err
+
+	case user.TeamName == "":
+		if err := s.repo.UpdateUser(desired); err != nil {
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		result.Added = append(result.Added, member.UserID)
+		return s.addEvent(models.PREvent{
+			EventType: models.EventUserTeamChanged,
+			ActorID:   actorID,
+			UserID:    member.UserID,
+			Reason:    reason,
+		})
+
+	case user.TeamName != teamName && !moveMembers:
+		result.Conflicts = append(result.Conflicts, member.UserID)
+		return nil
+
+	case user.TeamName != teamName:
+		change, err := s.changeUserTeam(user, teamName, true, actorID, reason)
+		if err != nil {
+			return err
+		}
+		result.Moved = append(result.Moved, member.UserID)
+		result.Reassignments = append(result.Reassignments, change.Reassignments...)
+
+		_, err = s.applyMemberChanges(user, member, false, result, actorID, reason)
+		return err
+	}
+
+	changed, err := s.applyMemberChanges(user, member, true, result, actorID, reason)
+	if err != nil {
+		return err
+	}
+
+	if changed {
+		result.Updated = append(result.Updated, member.UserID)
+	} else {
+		result.Unchanged = append(result.Unchanged, member.UserID)
+	}
+	return nil
+}
+
+// applyMemberChanges обновляет имя и активность участника команды.
+// При деактивации открытые ревью переназначаются, если reassign задан.
+func (s *ReviewService) applyMemberChanges(user *models.User, member models.TeamMember, reassign bool, result *models.TeamSyncResult, actorID, reason string) (bool, error) {
+	changed := false
+
+	if user.Username != member.Username {
+		user.Username = member.Username
+		if err := s.repo.UpdateUser(user); err != nil {
+			return false, fmt.Errorf("failed to update user: %w", err)
+		}
+		changed = true
+	}
+
+	if user.IsActive != member.IsActive {
+		if _, err := s.SetUserActive(user.UserID, member.IsActive, actorID, reason); err != nil {
+			return false, err
+		}
+		user.IsActive = member.IsActive
+		changed = true
+
+		if !member.IsActive && reassign {
+			reassignments, err := s.reassignOpenReviews(user.UserID, actorID, reason)
+			if err != nil {
+				return false, err
+			}
+			result.Reassignments = append(result.Reassignments, reassignments...)
+		}
+	}
+
+	return changed, nil
+}
+
+// validateRoster проверяет, что у всех участников есть id и имя и id не повторяются
+func validateRoster(members []models.TeamMember) error {
+	seen := make(map[string]bool, len(members))
+	for _, member := range members {
+		if member.UserID == "" || member.Username == "" || seen[member.UserID] {
+			return apperr.New(apperr.CodeBadRequest, "invalid team roster")
+		}
+		seen[member.UserID] = true
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"reviewtask/models"
+	"reviewtask/repo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pendingOutboxEvents — число событий, записанных в outbox и ещё не опубликованных
+func pendingOutboxEvents(t *testing.T, store *repo.MemoryStore) int {
+	t.Helper()
+	events, err := store.ClaimOutboxEvents(time.Now().Add(time.Hour), 1000, time.Hour)
+	require.NoError(t, err)
+	return len(events)
+}
+
+func TestMemorySyncTeamDiffAndIdempotency(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svc := NewReviewService(store)
+	createMemoryTeam(t, svc, "backend", "u1", "u2", "u3")
+	createMemoryTeam(t, svc, "frontend", "f1", "f2")
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{"u2", "u3"}, pr.AssignedReviewers)
+
+	roster := &models.Team{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "n1", Username: "Newcomer", IsActive: true},
+			{UserID: "f1", Username: "f1", IsActive: true},
+			{UserID: "u1", Username: "Alice Smith", IsActive: true},
+			{UserID: "u2", Username: "u2", IsActive: false},
+		},
+	}
+
+	result, err := svc.SyncTeam(roster, true, true, "admin", "hr sync")
+	require.NoError(t, err)
+	assert.False(t, result.CreatedTeam)
+	assert.Equal(t, []string{"n1"}, result.Added)
+	assert.Equal(t, []string{"f1"}, result.Moved)
+	assert.Equal(t, []string{"u1", "u2"}, result.Updated)
+	assert.Equal(t, []string{"u3"}, result.Deactivated)
+	assert.Empty(t, result.Unchanged)
+	require.Len(t, result.Reassignments, 2)
+	for _, r := range result.Reassignments {
+		assert.True(t, r.Reassigned, "%+v", r)
+	}
+
+	user, err := svc.GetUser("u1")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice Smith", user.Username)
+	user, err = svc.GetUser("f1")
+	require.NoError(t, err)
+	assert.Equal(t, "backend", user.TeamName)
+	user, err = svc.GetUser("u3")
+	require.NoError(t, err)
+	assert.False(t, user.IsActive)
+
+	updated, err := svc.GetPR("pr-1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"n1", "f1"}, updated.AssignedReviewers)
+
+	// Повторный вызов с тем же списком ничего не меняет и не пишет событий
+	history := eventTypes(t, svc, "pr-1")
+	pendingOutboxEvents(t, store)
+
+	result, err = svc.SyncTeam(roster, true, true, "admin", "hr sync")
+	require.NoError(t, err)
+	assert.Empty(t, result.Added)
+	assert.Empty(t, result.Moved)
+	assert.Empty(t, result.Updated)
+	assert.Empty(t, result.Deactivated)
+	assert.Empty(t, result.Reassignments)
+	assert.Equal(t, []string{"n1", "f1", "u1", "u2"}, result.Unchanged)
+
+	assert.Equal(t, history, eventTypes(t, svc, "pr-1"))
+	assert.Zero(t, pendingOutboxEvents(t, store))
+}
+
+func TestMemorySyncTeamCreatesTeam(t *testing.T) {
+	svc := newMemoryService(t)
+
+	result, err := svc.SyncTeam(&models.Team{
+		TeamName: "platform",
+		Members:  []models.TeamMember{{UserID: "p1", Username: "p1", IsActive: true}},
+	}, false, true, "bot", "")
+	require.NoError(t, err)
+	assert.True(t, result.CreatedTeam)
+	assert.Equal(t, []string{"p1"}, result.Added)
+
+	team, err := svc.repo.GetTeam("platform")
+	require.NoError(t, err)
+	require.Len(t, team.Members, 1)
+	assert.Equal(t, "p1", team.Members[0].UserID)
+}
+
+func TestMemorySyncTeamWithoutMovesReportsConflicts(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+	createMemoryTeam(t, svc, "frontend", "f1", "f2")
+
+	result, err := svc.SyncTeam(&models.Team{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "u1", Username: "u1", IsActive: true},
+			{UserID: "f1", Username: "Renamed", IsActive: false},
+		},
+	}, true, false, "lead", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"f1"}, result.Conflicts)
+	assert.Empty(t, result.Moved)
+	assert.Equal(t, []string{"u2"}, result.Deactivated)
+
+	user, err := svc.GetUser("f1")
+	require.NoError(t, err)
+	assert.Equal(t, "frontend", user.TeamName)
+	assert.Equal(t, "f1", user.Username)
+	assert.True(t, user.IsActive)
+}
+
+func TestMemorySyncTeamDeactivatesMissingBeforeReassigning(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "a", "b", "c", "d")
+
+	for i := 0; i < 10; i++ {
+		_, err := svc.CreatePRWithReviewers(fmt.Sprintf("pr-%d", i), "Add search", "a", false)
+		require.NoError(t, err)
+	}
+
+	result, err := svc.SyncTeam(&models.Team{
+		TeamName: "backend",
+		Members: []models.TeamMember{
+			{UserID: "a", Username: "a", IsActive: true},
+			{UserID: "e", Username: "e", IsActive: true},
+			{UserID: "f", Username: "f", IsActive: true},
+		},
+	}, true, true, "admin", "reorg")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"b", "c", "d"}, result.Deactivated)
+
+	// Ревью выбывших уходят только оставшимся участникам, а не другим выбывшим
+	require.Len(t, result.Reassignments, 20)
+	for _, reassignment := range result.Reassignments {
+		assert.True(t, reassignment.Reassigned, reassignment.PullRequestID)
+		assert.Contains(t, []string{"e", "f"}, reassignment.NewUserID)
+	}
+	for i := 0; i < 10; i++ {
+		pr, err := svc.GetPR(fmt.Sprintf("pr-%d", i))
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"e", "f"}, pr.AssignedReviewers)
+	}
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"reviewtask/apperr"
+	"reviewtask/models"
+)
+
+// CreateWebhookSubscription подписывает URL на события команды или, при пустом
+// TeamName, всех команд. Без секрета генерируется случайный; он возвращается
+// только в ответе на создание.
+func (s *ReviewService) CreateWebhookSubscription(sub *models.WebhookSubscription) (*models.WebhookSubscription, error) {
+	u, err := url.Parse(sub.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, apperr.New(apperr.CodeBadRequest, "url must be an absolute http(s) URL")
+	}
+
+	for _, eventType := range sub.EventTypes {
+		if !models.IsWebhookEvent(eventType) {
+			return nil, apperr.New(apperr.CodeBadRequest, fmt.Sprintf("no webhooks for event type %q", eventType))
+		}
+	}
+
+	if sub.TeamName != "" {
+		if _, err := s.repo.GetTeam(sub.TeamName); err != nil {
+			return nil, err
+		}
+	}
+
+	if sub.Secret == "" {
+		if sub.Secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.CreateWebhookSubscription(sub); err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+// GetWebhookSubscription возвращает подписку без секрета
+func (s *ReviewService) GetWebhookSubscription(subscriptionID int64) (*models.WebhookSubscription, error) {
+	sub, err := s.repo.GetWebhookSubscription(subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.Secret = ""
+	return sub, nil
+}
+
+// ListWebhookSubscriptions возвращает подписки, получающие события команды
+// teamName (включая глобальные), или все подписки при пустом teamName
+func (s *ReviewService) ListWebhookSubscriptions(teamName string) ([]models.WebhookSubscription, error) {
+	if teamName != "" {
+		if _, err := s.repo.GetTeam(teamName); err != nil {
+			return nil, err
+		}
+	}
+
+	subs, err := s.repo.ListWebhookSubscriptions(teamName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+// DeleteWebhookSubscription удаляет подписку вместе с её очередью и dead letter
+func (s *ReviewService) DeleteWebhookSubscription(subscriptionID int64) error {
+	return s.repo.DeleteWebhookSubscription(subscriptionID)
+}
+
+// ListWebhookDeadLetters возвращает недоставленные вебхуки подписки, при 0 — всех подписок
+func (s *ReviewService) ListWebhookDeadLetters(subscriptionID int64) ([]models.WebhookDeadLetter, error) {
+	if subscriptionID != 0 {
+		if _, err := s.repo.GetWebhookSubscription(subscriptionID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.repo.ListWebhookDeadLetters(subscriptionID)
+}
+
+// RedeliverWebhook возвращает недоставленный вебхук в очередь с новым счётчиком попыток
+func (s *ReviewService) RedeliverWebhook(deadLetterID int64) (*models.WebhookDelivery, error) {
+	return s.repo.RequeueWebhookDeadLetter(deadLetterID)
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
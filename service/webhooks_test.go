@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"reviewtask/apperr"
+	"reviewtask/models"
+	"reviewtask/repo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queuedWebhooks забирает из очереди все доставки
+func queuedWebhooks(t *testing.T, store *repo.MemoryStore) []models.WebhookDelivery {
+	t.Helper()
+
+	deliveries, err := store.ClaimWebhookDeliveries(time.Now().Add(time.Hour), 1000, time.Hour)
+	require.NoError(t, err)
+	return deliveries
+}
+
+func TestMemoryWebhookSubscriptions(t *testing.T) {
+	svc := newMemoryService(t)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	sub, err := svc.CreateWebhookSubscription(&models.WebhookSubscription{
+		TeamName:   "backend",
+		URL:        "https://hooks.example.com/review",
+		EventTypes: []models.EventType{models.EventPRMerged},
+	})
+	require.NoError(t, err)
+	assert.NotZero(t, sub.ID)
+	assert.Len(t, sub.Secret, 64, "secret is generated when not provided")
+
+	subs, err := svc.ListWebhookSubscriptions("backend")
+	require.NoError(t, err)
+	require.Len(t, subs, 1)
+	assert.Empty(t, subs[0].Secret, "secret is returned only on creation")
+
+	invalid := []*models.WebhookSubscription{
+		{URL: "hooks.example.com"},
+		{URL: "ftp://hooks.example.com"},
+		{URL: "https://hooks.example.com", EventTypes: []models.EventType{models.EventReviewSubmitted}},
+	}
+	for _, s := range invalid {
+		_, err := svc.CreateWebhookSubscription(s)
+		assert.ErrorIs(t, err, apperr.New(apperr.CodeBadRequest, ""), s.URL)
+	}
+
+	_, err = svc.CreateWebhookSubscription(&models.WebhookSubscription{TeamName: "nope", URL: "https://hooks.example.com"})
+	assert.ErrorIs(t, err, apperr.ErrTeamNotFound)
+
+	require.NoError(t, svc.DeleteWebhookSubscription(sub.ID))
+	assert.ErrorIs(t, svc.DeleteWebhookSubscription(sub.ID), apperr.ErrWebhookNotFound)
+}
+
+func TestMemoryWebhookRedeliver(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svc := NewReviewService(store)
+	createMemoryTeam(t, svc, "backend", "u1", "u2")
+
+	sub, err := svc.CreateWebhookSubscription(&models.WebhookSubscription{URL: "https://all.example.com"})
+	require.NoError(t, err)
+	require.NoError(t, store.EnqueueWebhookDelivery(&models.WebhookDelivery{
+		SubscriptionID: sub.ID,
+		EventID:        7,
+		EventType:      models.EventUserDeactivated,
+		Payload:        []byte(`{}`),
+	}))
+
+	deliveries := queuedWebhooks(t, store)
+	require.Len(t, deliveries, 1)
+	require.NoError(t, store.DeadLetterWebhookDelivery(deliveries[0].ID, "unexpected status 500"))
+
+	letters, err := svc.ListWebhookDeadLetters(sub.ID)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, 1, letters[0].Attempts)
+	assert.Equal(t, "unexpected status 500", letters[0].LastError)
+
+	delivery, err := svc.RedeliverWebhook(letters[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, deliveries[0].EventID, delivery.EventID)
+	assert.Zero(t, delivery.Attempts)
+
+	_, err = svc.RedeliverWebhook(letters[0].ID)
+	assert.ErrorIs(t, err, apperr.ErrDeadLetterNotFound)
+	assert.Len(t, queuedWebhooks(t, store), 1)
+}
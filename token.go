@@ -0,0 +1,43 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"reviewtask/auth"
+)
+
+// runToken выпускает bearer-токен пользователя, подписанный AUTH_TOKEN_SECRET
+func runToken(args []string) int {
+	flags := flag.NewFlagSet("token", flag.ContinueOnError)
+	userID := flags.String("user", "", "user_id the token is issued for")
+	role := flags.String("role", string(auth.RoleMember), "role: admin, team-lead, member or bot")
+	ttl := flags.Duration("ttl", 24*time.Hour, "token lifetime")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	secret := os.Getenv("AUTH_TOKEN_SECRET")
+	if secret == "" {
+		log.Print("AUTH_TOKEN_SECRET is not set")
+		return 1
+	}
+
+	parsed, err := auth.ParseRole(*role)
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+
+	token, err := auth.IssueToken([]byte(secret), *userID, parsed, *ttl, time.Now())
+	if err != nil {
+		log.Print(err)
+		return 2
+	}
+
+	fmt.Println(token)
+	return 0
+}
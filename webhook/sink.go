@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"context"
+
+	"reviewtask/models"
+	"reviewtask/repo"
+)
+
+// Sink — приёмник outbox: ставит доставки события подписчикам его команды
+// и глобальным подписчикам. Повторная публикация события не создаёт вторую
+// доставку, пока первая ждёт в очереди.
+type Sink struct {
+	store repo.Store
+}
+
+func NewSink(store repo.Store) *Sink {
+	return &Sink{store: store}
+}
+
+func (s *Sink) Name() string {
+	return "webhook"
+}
+
+func (s *Sink) Publish(ctx context.Context, event models.OutboxEvent) error {
+	if !models.IsWebhookEvent(event.EventType) {
+		return nil
+	}
+
+	return s.store.WithTx(func(tx repo.Store) error {
+		subs, err := tx.ListWebhookSubscriptions(event.TeamName)
+		if err != nil {
+			return err
+		}
+
+		for _, sub := range subs {
+			if !sub.Matches(event.TeamName, event.EventType) {
+				continue
+			}
+
+			err := tx.EnqueueWebhookDelivery(&models.WebhookDelivery{
+				SubscriptionID: sub.ID,
+				EventID:        event.EventID,
+				EventType:      event.EventType,
+				Payload:        event.Payload,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
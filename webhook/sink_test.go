@@ -0,0 +1,114 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"reviewtask/models"
+	"reviewtask/outbox"
+	"reviewtask/repo"
+	"reviewtask/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSinkQueuesDeliveriesForMatchingSubscriptions(t *testing.T) {
+	store := repo.NewMemoryStore()
+	svc := service.NewReviewService(store)
+	for team, ids := range map[string][]string{"backend": {"u1", "u2", "u3"}, "frontend": {"f1", "f2"}} {
+		members := []models.TeamMember{}
+		for _, id := range ids {
+			members = append(members, models.TeamMember{UserID: id, Username: id, IsActive: true})
+		}
+		require.NoError(t, svc.CreateTeam(&models.Team{TeamName: team, Members: members}))
+	}
+
+	backend, err := svc.CreateWebhookSubscription(&models.WebhookSubscription{TeamName: "backend", URL: "https://backend.example.com"})
+	require.NoError(t, err)
+	global, err := svc.CreateWebhookSubscription(&models.WebhookSubscription{
+		URL:        "https://all.example.com",
+		EventTypes: []models.EventType{models.EventPRMerged, models.EventUserDeactivated},
+	})
+	require.NoError(t, err)
+	_, err = svc.CreateWebhookSubscription(&models.WebhookSubscription{TeamName: "frontend", URL: "https://frontend.example.com"})
+	require.NoError(t, err)
+
+	dispatcher := outbox.NewDispatcher(store, outbox.DefaultConfig(), NewSink(store))
+	queued := func() []models.WebhookDelivery {
+		t.Helper()
+		_, err := dispatcher.ProcessBatch(context.Background())
+		require.NoError(t, err)
+		deliveries, err := store.ClaimWebhookDeliveries(time.Now().Add(time.Hour), 100, time.Hour)
+		require.NoError(t, err)
+		for _, d := range deliveries {
+			require.NoError(t, store.CompleteWebhookDelivery(d.ID))
+		}
+		return deliveries
+	}
+
+	pr, err := svc.CreatePRWithReviewers("pr-1", "Add search", "u1", false)
+	require.NoError(t, err)
+
+	deliveries := queued()
+	require.Len(t, deliveries, 1+len(pr.AssignedReviewers))
+	for _, d := range deliveries {
+		assert.Equal(t, backend.ID, d.SubscriptionID)
+	}
+	assert.Equal(t, models.EventPRCreated, deliveries[0].EventType)
+	assert.Equal(t, models.EventReviewerAssigned, deliveries[1].EventType)
+
+	var envelope models.EventEnvelope
+	require.NoError(t, json.Unmarshal(deliveries[1].Payload, &envelope))
+	assert.Equal(t, "backend", envelope.TeamName)
+	assert.Equal(t, models.EventIdempotencyKey(deliveries[1].EventID), envelope.IdempotencyKey)
+
+	// Вердикты не отправляются; мердж получают и команда, и глобальный подписчик
+	for _, reviewer := range pr.AssignedReviewers {
+		_, err := svc.SubmitReview("pr-1", reviewer, models.VerdictApproved, "")
+		require.NoError(t, err)
+	}
+	_, err = svc.MergePR("pr-1", "u1")
+	require.NoError(t, err)
+
+	deliveries = queued()
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, []int64{backend.ID, global.ID}, []int64{deliveries[0].SubscriptionID, deliveries[1].SubscriptionID})
+
+	_, err = svc.SetUserActive("f2", false, "admin", "left")
+	require.NoError(t, err)
+
+	deliveries = queued()
+	require.Len(t, deliveries, 2, "frontend and global subscribers")
+	for _, d := range deliveries {
+		assert.Equal(t, models.EventUserDeactivated, d.EventType)
+	}
+}
+
+func TestSinkRepublishDoesNotDuplicatePendingDelivery(t *testing.T) {
+	store := repo.NewMemoryStore()
+	sub := &models.WebhookSubscription{URL: "https://all.example.com", Secret: "s"}
+	require.NoError(t, store.CreateWebhookSubscription(sub))
+
+	event := models.OutboxEvent{
+		IdempotencyKey: models.EventIdempotencyKey(5),
+		EventID:        5,
+		EventType:      models.EventUserDeactivated,
+		Payload:        []byte(`{}`),
+	}
+	sink := NewSink(store)
+	require.NoError(t, sink.Publish(context.Background(), event))
+	require.NoError(t, sink.Publish(context.Background(), event))
+
+	// События без вебхуков приёмник пропускает
+	skipped := event
+	skipped.EventID, skipped.EventType = 6, models.EventReviewSubmitted
+	require.NoError(t, sink.Publish(context.Background(), skipped))
+
+	deliveries, err := store.ClaimWebhookDeliveries(time.Now().Add(time.Hour), 100, time.Hour)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, int64(5), deliveries[0].EventID)
+}
@@ -0,0 +1,161 @@
+// Package webhook доставляет исходящие вебхуки из очереди webhook_deliveries.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"reviewtask/models"
+	"reviewtask/repo"
+)
+
+// Config — параметры доставки
+type Config struct {
+	// PollInterval — пауза между опросами пустой очереди
+	PollInterval time.Duration
+	// BatchSize — сколько доставок забирается за раз
+	BatchSize int
+	// MaxAttempts — после стольких неудачных попыток доставка уходит в dead letter
+	MaxAttempts int
+	// BaseBackoff и MaxBackoff — задержка перед повтором: BaseBackoff * 2^(n-1), не больше MaxBackoff
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Timeout — время ожидания ответа подписчика
+	Timeout time.Duration
+}
+
+func DefaultConfig() Config {
+	return Config{
+		PollInterval: time.Second,
+		BatchSize:    20,
+		MaxAttempts:  8,
+		BaseBackoff:  5 * time.Second,
+		MaxBackoff:   time.Hour,
+		Timeout:      10 * time.Second,
+	}
+}
+
+// Backoff — задержка перед следующей попыткой после attempt неудачных
+func (c Config) Backoff(attempt int) time.Duration {
+	delay := c.BaseBackoff
+	for i := 1; i < attempt && delay < c.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > c.MaxBackoff {
+		delay = c.MaxBackoff
+	}
+	return delay
+}
+
+// Lease — на сколько откладываются забранные доставки. Пачка отправляется
+// последовательно, поэтому запас покрывает ожидание ответа на каждую доставку
+// пачки и ещё одну: иначе хвост пачки успевает вернуться в очередь и уходит дважды
+func (c Config) Lease() time.Duration {
+	return time.Duration(c.BatchSize+1) * c.Timeout
+}
+
+// Worker разбирает очередь доставок: успешные удаляет, неудачные
+// откладывает с экспоненциальной задержкой, исчерпавшие попытки
+// переносит в dead letter
+type Worker struct {
+	queue  repo.WebhookQueue
+	client *http.Client
+	cfg    Config
+	now    func() time.Time
+}
+
+func NewWorker(queue repo.WebhookQueue, cfg Config) *Worker {
+	return &Worker{
+		queue:  queue,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+		now:    time.Now,
+	}
+}
+
+// Run обрабатывает очередь до отмены ctx
+func (w *Worker) Run(ctx context.Context) {
+	for {
+		n, err := w.ProcessBatch(ctx)
+		if err != nil {
+			log.Printf("webhook worker: %v", err)
+		}
+
+		// Полная пачка — в очереди, скорее всего, есть ещё доставки
+		if err == nil && n == w.cfg.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.cfg.PollInterval):
+		}
+	}
+}
+
+// ProcessBatch отправляет одну пачку доставок и возвращает их число
+func (w *Worker) ProcessBatch(ctx context.Context) (int, error) {
+	// Доставки откладываются на время отправки всей пачки: если обработчик
+	// упадёт, их подхватит опрос после истечения аренды
+	deliveries, err := w.queue.ClaimWebhookDeliveries(w.now(), w.cfg.BatchSize, w.cfg.Lease())
+	if err != nil {
+		return 0, fmt.Errorf("claim deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		if err := w.handle(ctx, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+func (w *Worker) handle(ctx context.Context, delivery models.WebhookDelivery) error {
+	sendErr := w.send(ctx, delivery)
+	if sendErr == nil {
+		return w.queue.CompleteWebhookDelivery(delivery.ID)
+	}
+
+	attempt := delivery.Attempts + 1
+	if attempt >= w.cfg.MaxAttempts {
+		log.Printf("webhook delivery %d to %s failed after %d attempts: %v", delivery.ID, delivery.URL, attempt, sendErr)
+		return w.queue.DeadLetterWebhookDelivery(delivery.ID, sendErr.Error())
+	}
+	return w.queue.RetryWebhookDelivery(delivery.ID, w.now().Add(w.cfg.Backoff(attempt)), sendErr.Error())
+}
+
+// send выполняет POST с подписанным телом; успехом считается ответ 2xx
+func (w *Worker) send(ctx context.Context, delivery models.WebhookDelivery) error {
+	timestamp := w.now().Unix()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "review-service-webhooks")
+	req.Header.Set(HeaderEvent, string(delivery.EventType))
+	// Ключ идемпотентности события не меняется между повторами: по нему
+	// получатель отбрасывает дубликаты
+	req.Header.Set(HeaderID, models.EventIdempotencyKey(delivery.EventID))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"reviewtask/models"
+	"reviewtask/repo"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// receiver — локальный подписчик: записывает запросы и отвечает статусами по очереди
+type receiver struct {
+	mu       sync.Mutex
+	requests []*http.Request
+	bodies   [][]byte
+	statuses []int
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (r *receiver) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.requests)
+}
+
+type testClock struct{ t time.Time }
+
+func (c *testClock) now() time.Time          { return c.t }
+func (c *testClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// newTestWorker создаёт хранилище с одной доставкой на адрес подписчика
+func newTestWorker(t *testing.T, rcv *receiver, cfg Config) (*Worker, *repo.MemoryStore, *testClock) {
+	t.Helper()
+
+	server := httptest.NewServer(rcv)
+	t.Cleanup(server.Close)
+
+	store := repo.NewMemoryStore()
+	require.NoError(t, store.CreateTeam(&models.Team{TeamName: "backend"}))
+
+	sub := &models.WebhookSubscription{TeamName: "backend", URL: server.URL, Secret: "s3cret"}
+	require.NoError(t, store.CreateWebhookSubscription(sub))
+
+	payload, err := json.Marshal(models.EventEnvelope{
+		IdempotencyKey: models.EventIdempotencyKey(42),
+		Event:          models.PREvent{ID: 42, EventType: models.EventPRMerged, PullRequestID: "pr-1"},
+		TeamName:       "backend",
+	})
+	require.NoError(t, err)
+	require.NoError(t, store.EnqueueWebhookDelivery(&models.WebhookDelivery{
+		SubscriptionID: sub.ID,
+		EventID:        42,
+		EventType:      models.EventPRMerged,
+		Payload:        payload,
+	}))
+
+	clock := &testClock{t: time.Now()}
+	w := NewWorker(store, cfg)
+	w.now = clock.now
+	return w, store, clock
+}
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.MaxAttempts = 3
+	cfg.BaseBackoff = time.Minute
+	cfg.MaxBackoff = 10 * time.Minute
+	cfg.Timeout = time.Second
+	return cfg
+}
+
+func TestWorkerDeliversSignedPayload(t *testing.T) {
+	rcv := &receiver{}
+	w, store, _ := newTestWorker(t, rcv, testConfig())
+
+	n, err := w.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	require.Equal(t, 1, rcv.count())
+
+	req, body := rcv.requests[0], rcv.bodies[0]
+	assert.Equal(t, "PR_MERGED", req.Header.Get(HeaderEvent))
+	assert.Equal(t, "pr-event-42", req.Header.Get(HeaderID))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+
+	timestamp, err := strconv.ParseInt(req.Header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.True(t, Verify("s3cret", timestamp, body, req.Header.Get(HeaderSignature)))
+	assert.False(t, Verify("other", timestamp, body, req.Header.Get(HeaderSignature)))
+
+	var payload models.EventEnvelope
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, "pr-1", payload.Event.PullRequestID)
+
+	// Доставленное удаляется из очереди
+	n, err = w.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	letters, err := store.ListWebhookDeadLetters(0)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+}
+
+func TestWorkerRetriesWithBackoff(t *testing.T) {
+	rcv := &receiver{statuses: []int{http.StatusInternalServerError, http.StatusBadGateway}}
+	w, _, clock := newTestWorker(t, rcv, testConfig())
+	ctx := context.Background()
+
+	_, err := w.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, rcv.count())
+
+	// До истечения задержки повтора нет
+	clock.advance(time.Minute - time.Second)
+	n, err := w.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	clock.advance(time.Second)
+	_, err = w.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, rcv.count())
+
+	// Вторая задержка вдвое длиннее первой
+	clock.advance(time.Minute)
+	n, err = w.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	clock.advance(time.Minute)
+	_, err = w.ProcessBatch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, rcv.count())
+
+	// Повторы несут тот же ID события
+	for _, req := range rcv.requests {
+		assert.Equal(t, "pr-event-42", req.Header.Get(HeaderID))
+	}
+}
+
+func TestWorkerMovesExhaustedDeliveryToDeadLetter(t *testing.T) {
+	rcv := &receiver{statuses: []int{500, 500, 500}}
+	w, store, clock := newTestWorker(t, rcv, testConfig())
+
+	for i := 0; i < 3; i++ {
+		_, err := w.ProcessBatch(context.Background())
+		require.NoError(t, err)
+		clock.advance(time.Hour)
+	}
+	assert.Equal(t, 3, rcv.count())
+
+	letters, err := store.ListWebhookDeadLetters(0)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, 3, letters[0].Attempts)
+	assert.Equal(t, int64(42), letters[0].EventID)
+	assert.Contains(t, letters[0].LastError, "unexpected status 500")
+
+	n, err := w.ProcessBatch(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, n, "dead letters are not retried automatically")
+}
+
+func TestBackoff(t *testing.T) {
+	cfg := Config{BaseBackoff: 5 * time.Second, MaxBackoff: time.Minute}
+
+	assert.Equal(t, 5*time.Second, cfg.Backoff(1))
+	assert.Equal(t, 10*time.Second, cfg.Backoff(2))
+	assert.Equal(t, 40*time.Second, cfg.Backoff(4))
+	assert.Equal(t, time.Minute, cfg.Backoff(5))
+	assert.Equal(t, time.Minute, cfg.Backoff(30))
+}
+
+func TestLeaseCoversWholeBatch(t *testing.T) {
+	cfg := Config{BatchSize: 20, Timeout: 10 * time.Second}
+	assert.Equal(t, 210*time.Second, cfg.Lease())
+
+	// Пока пачка отправляется, её доставки не забирает соседний опрос
+	w, store, clock := newTestWorker(t, &receiver{}, testConfig())
+	claimed, err := store.ClaimWebhookDeliveries(clock.now(), w.cfg.BatchSize, w.cfg.Lease())
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+
+	clock.advance(time.Duration(w.cfg.BatchSize) * w.cfg.Timeout)
+	again, err := store.ClaimWebhookDeliveries(clock.now(), w.cfg.BatchSize, w.cfg.Lease())
+	require.NoError(t, err)
+	assert.Empty(t, again)
+}